@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/patrickneise/dashboard/internal/app"
+	"github.com/patrickneise/dashboard/internal/config"
+	"github.com/patrickneise/dashboard/internal/logging"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+func runConfig(args []string) int {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err == nil {
+		err = checkDeployment(cfg)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "configuration is invalid:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintln(os.Stderr, "  - "+line)
+		}
+		return 1
+	}
+
+	fmt.Printf("configuration ok (env=%s, addr=%s, db=%s, sso=%t)\n",
+		cfg.Env, cfg.Addr, cfg.DatabasePath, cfg.OIDCEnabled())
+	return 0
+}
+
+// checkDeployment covers what Config.Validate cannot see on its own: references to
+// widgets and files outside the config.
+func checkDeployment(cfg config.Config) error {
+	var errs []error
+
+	reg := app.Widgets(cfg, app.WidgetDeps{HTTP: app.NewHTTPClient(), Log: logging.NewTo(os.Stderr, logging.ModeProd)})
+	specs := reg.List()
+	for key := range cfg.WidgetGroups {
+		if !slices.ContainsFunc(specs, func(s widgetkit.Spec) bool { return s.Key == key }) {
+			errs = append(errs, fmt.Errorf("WIDGET_GROUPS: unknown widget %q", key))
+		}
+	}
+
+	if cfg.StaticFromDisk {
+		if st, err := os.Stat(cfg.StaticDir); err != nil || !st.IsDir() {
+			errs = append(errs, fmt.Errorf("STATIC_DIR %q is not a directory (STATIC_FROM_DISK is on)", cfg.StaticDir))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,83 +2,77 @@ package main
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	"github.com/patrickneise/dashboard/internal/app"
 	"github.com/patrickneise/dashboard/internal/config"
 	"github.com/patrickneise/dashboard/internal/logging"
 )
 
-func main() {
-	// Load Config
-	cfg, err := config.Load()
-	if err != nil {
-		slog.Error("config_error", slog.Any("err", err))
-		os.Exit(1)
-	}
-
-	// Setup Logging
-	mode := logging.ModeDev
-	if cfg.Env == "prod" {
-		mode = logging.ModeProd
-	}
-	log := logging.New(mode)
-	slog.SetDefault(log)
+const usage = `Usage: dashboard <command> [arguments]
 
-	// Build App
-	a, err := app.Build(cfg, log)
-	if err != nil {
-		log.Error("app_build_failed", slog.Any("err", err))
-		os.Exit(1)
-	}
+Commands:
+  serve                       run the web server (default)
+  worker [-run-once]          fetch widgets on a schedule into the database
+  widgets list                list registered widgets
+  widgets fetch [-o json|text] [-user name] <key>
+                              fetch one widget and print its view model
+  users groups <name> [group,...]
+                              show or replace a user's groups ("" clears them)
+  config check                validate configuration from the environment
+  migrate up|status           apply or list database migrations
+  migrate down [-steps N]     revert the last N migrations (0 = all)
+  migrate force <version>     record a version and clear the dirty flag
 
-	// Configure Server
-	srv := &http.Server{
-		Addr:              cfg.Addr,
-		Handler:           a.Router,
-		ReadHeaderTimeout: 5 * time.Second,
-		ReadTimeout:       15 * time.Second,
-		WriteTimeout:      30 * time.Second,
-		IdleTimeout:       60 * time.Second,
-		MaxHeaderBytes:    1 << 20,
-	}
+Configuration is read from environment variables (see README).
+`
 
-	// Server Start/Stop
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:]))
+}
 
-	errCh := make(chan error, 1)
-	go func() {
-		log.Info("starting_server", slog.String("addr", cfg.Addr))
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			errCh <- err
-		}
-		close(errCh)
-	}()
+// run dispatches a subcommand and returns the process exit code.
+func run(ctx context.Context, args []string) int {
+	cmd := "serve"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
 
-	select {
-	case <-ctx.Done():
-		log.Info("shutdown_signal_received")
-	case err := <-errCh:
-		if err != nil {
-			log.Error("server_error", slog.Any("err", err))
-			os.Exit(1)
-		}
+	switch cmd {
+	case "serve":
+		return runServe(ctx, args)
+	case "worker":
+		return runWorker(ctx, args)
+	case "widgets":
+		return runWidgets(ctx, args)
+	case "migrate":
+		return runMigrate(ctx, args)
+	case "users":
+		return runUsers(ctx, args)
+	case "config":
+		return runConfig(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		return 2
 	}
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// loadConfig loads configuration and a logger writing to w.
+func loadConfig(w io.Writer) (config.Config, *slog.Logger, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		slog.New(slog.NewTextHandler(w, nil)).Error("config_error", slog.Any("err", err))
+		return config.Config{}, nil, false
+	}
 
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Error("shutdown_error", slog.Any("err", err))
-		_ = srv.Close()
-	} else {
-		log.Info("server_stopped")
+	mode := logging.ModeDev
+	if cfg.Env == config.EnvProd {
+		mode = logging.ModeProd
 	}
+	return cfg, logging.NewTo(w, mode), true
 }
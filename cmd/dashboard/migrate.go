@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/patrickneise/dashboard/internal/migrate"
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+func runMigrate(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	sub, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("migrate "+sub, flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "down: number of migrations to revert (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, _, ok := loadConfig(os.Stderr)
+	if !ok {
+		return 1
+	}
+
+	db, err := store.OpenDB(ctx, cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+
+	m, err := store.Migrator(db)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch sub {
+	case "up":
+		applied, err := m.Up(ctx)
+		printMigrations("applied", applied)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := m.Down(ctx, *steps)
+		printMigrations("reverted", reverted)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("database: %s\nversion:  %d", cfg.DatabasePath, st.Version)
+		if st.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+		printMigrations("applied", st.Applied)
+		printMigrations("pending", st.Pending)
+	case "force":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: dashboard migrate force <version>")
+			return 2
+		}
+		version, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", fs.Arg(0))
+			return 2
+		}
+		if err := m.Force(ctx, version); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("version set to %d\n", version)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", sub, usage)
+		return 2
+	}
+	return 0
+}
+
+func printMigrations(verb string, ms []migrate.Migration) {
+	for _, m := range ms {
+		fmt.Printf("%-8s  %06d_%s\n", verb, m.Version, m.Name)
+	}
+}
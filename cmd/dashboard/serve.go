@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/app"
+)
+
+func runServe(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Load Config
+	cfg, log, ok := loadConfig(os.Stdout)
+	if !ok {
+		return 1
+	}
+	slog.SetDefault(log)
+
+	// Build App
+	a, err := app.Build(ctx, cfg, log)
+	if err != nil {
+		log.Error("app_build_failed", slog.Any("err", err))
+		return 1
+	}
+	defer func() { _ = a.Close() }()
+
+	// Configure Server
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           a.Router,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+	}
+
+	// Server Start/Stop
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("starting_server", slog.String("addr", cfg.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Info("shutdown_signal_received")
+	case err := <-errCh:
+		if err != nil {
+			log.Error("server_error", slog.Any("err", err))
+			return 1
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("shutdown_error", slog.Any("err", err))
+		_ = srv.Close()
+	} else {
+		log.Info("server_stopped")
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+// runUsers manages accounts. Groups matter for AUTH_ALLOWED_GROUPS and WIDGET_GROUPS;
+// SSO users get theirs from the groups claim, local accounts only from here.
+func runUsers(ctx context.Context, args []string) int {
+	if len(args) < 2 || len(args) > 3 || args[0] != "groups" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	username := args[1]
+
+	cfg, _, ok := loadConfig(os.Stderr)
+	if !ok {
+		return 1
+	}
+
+	st, err := store.Open(ctx, cfg.DatabasePath, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() { _ = st.Close() }()
+
+	u, err := st.Queries.GetUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "unknown user %q\n", username)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(args) == 3 {
+		groups := splitGroups(args[2])
+		err := st.InTx(ctx, func(q *store.Queries) error {
+			if err := q.DeleteUserGroups(ctx, u.ID); err != nil {
+				return err
+			}
+			for _, g := range groups {
+				if err := q.AddUserGroup(ctx, store.AddUserGroupParams{UserID: u.ID, GroupName: g}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if u.PasswordHash == "" {
+			fmt.Fprintln(os.Stderr, "note: this is an SSO account; its next login replaces these groups")
+		}
+	}
+
+	groups, err := st.Queries.ListUserGroups(ctx, u.ID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%s: %s\n", u.Username, strings.Join(groups, ","))
+	return 0
+}
+
+// splitGroups parses a comma separated group list; "" clears the groups.
+func splitGroups(v string) []string {
+	var out []string
+	for _, g := range strings.Split(v, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			out = append(out, g)
+		}
+	}
+	return out
+}
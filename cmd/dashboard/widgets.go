@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/app"
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/prefs"
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+func runWidgets(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return runWidgetsList(args[1:])
+	case "fetch":
+		return runWidgetsFetch(ctx, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown widgets command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func runWidgetsList(args []string) int {
+	fs := flag.NewFlagSet("widgets list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, log, ok := loadConfig(os.Stderr)
+	if !ok {
+		return 1
+	}
+	reg := app.Widgets(cfg, app.WidgetDeps{HTTP: app.NewHTTPClient(), Log: log})
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tTITLE\tJSON\tNEEDS\tGROUPS")
+	for _, s := range reg.List() {
+		_, data := s.Handler.(widgetkit.DataHandler)
+		groups := strings.Join(s.Groups, ",")
+		if groups == "" {
+			groups = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%s\n", s.Key, s.Title, data, needs(s), groups)
+	}
+	_ = tw.Flush()
+	return 0
+}
+
+func runWidgetsFetch(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("widgets fetch", flag.ContinueOnError)
+	output := fs.String("o", "json", "output format: json or text")
+	timeout := fs.Duration("timeout", 15*time.Second, "give up after this long")
+	user := fs.String("user", "", "fetch as this user (their preferences; required for per-user widgets)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || (*output != "json" && *output != "text") {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	key := fs.Arg(0)
+
+	cfg, log, ok := loadConfig(os.Stderr)
+	if !ok {
+		return 1
+	}
+	deps := app.WidgetDeps{HTTP: app.NewHTTPClient(), Log: log}
+
+	spec, ok := findWidget(app.Widgets(cfg, deps), key)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown widget %q (see `dashboard widgets list`)\n", key)
+		return 1
+	}
+	if spec.PerUser && *user == "" {
+		fmt.Fprintf(os.Stderr, "widget %q is per user; pass -user <name>\n", key)
+		return 2
+	}
+
+	// Database-backed widgets, and fetching as a user, need the store.
+	if spec.Store || *user != "" {
+		st, err := store.Open(ctx, cfg.DatabasePath, false)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer func() { _ = st.Close() }()
+
+		deps.Store = st
+		deps.Prefs = prefs.NewService(st.Queries, log)
+		spec, _ = findWidget(app.Widgets(cfg, deps), key)
+
+		if *user != "" {
+			ctx, err = signIn(ctx, deps, *user)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if u, _ := auth.UserFromContext(ctx); !u.InAnyGroup(spec.Groups) {
+				fmt.Fprintf(os.Stderr, "user %q may not see widget %q (groups: %s)\n",
+					*user, key, strings.Join(spec.Groups, ","))
+				return 1
+			}
+		}
+	}
+
+	dh, ok := spec.Handler.(widgetkit.DataHandler)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "widget %q does not expose data\n", key)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	env, err := dh.LoadData(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetch %s: %v\n", key, err)
+		return 1
+	}
+
+	if *output == "text" {
+		err = writeText(os.Stdout, env)
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(env)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func findWidget(reg *widgetkit.Registry, key string) (widgetkit.Spec, bool) {
+	idx := slices.IndexFunc(reg.List(), func(s widgetkit.Spec) bool { return s.Key == key })
+	if idx < 0 {
+		return widgetkit.Spec{}, false
+	}
+	return reg.List()[idx], true
+}
+
+// needs summarizes what widgets fetch needs for a widget: "db", "db,user" or "-".
+func needs(s widgetkit.Spec) string {
+	switch {
+	case s.PerUser:
+		return "db,user"
+	case s.Store:
+		return "db"
+	default:
+		return "-"
+	}
+}
+
+// signIn returns ctx with username (and their groups and preferences) attached, as
+// the session middleware does for a signed-in request.
+func signIn(ctx context.Context, d app.WidgetDeps, username string) (context.Context, error) {
+	row, err := d.Store.Queries.GetUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unknown user %q", username)
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups, err := d.Store.Queries.ListUserGroups(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := d.Prefs.Load(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = auth.WithUser(ctx, auth.User{ID: row.ID, Username: row.Username, Groups: groups})
+	return prefs.WithPreferences(ctx, p), nil
+}
+
+// writeText prints v as "path: value" lines (via its JSON form), e.g.
+// "data.items[0].title: ...", which is easy to grep.
+func writeText(w io.Writer, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var tree any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	flatten(tw, "", tree)
+	return tw.Flush()
+}
+
+func flatten(w io.Writer, path string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flatten(w, p, v[k])
+		}
+	case []any:
+		for i, e := range v {
+			flatten(w, path+"["+strconv.Itoa(i)+"]", e)
+		}
+	case nil:
+		fmt.Fprintf(w, "%s:\t-\n", path)
+	default:
+		fmt.Fprintf(w, "%s:\t%v\n", path, v)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/patrickneise/dashboard/internal/app"
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/internal/worker"
+)
+
+func runWorker(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	once := fs.Bool("run-once", false, "sync every widget once and exit")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, log, ok := loadConfig(os.Stdout)
+	if !ok {
+		return 1
+	}
+	slog.SetDefault(log)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	st, err := store.Open(ctx, cfg.DatabasePath, cfg.AutoMigrate)
+	if err != nil {
+		log.Error("store_open_failed", slog.Any("err", err))
+		return 1
+	}
+	defer func() { _ = st.Close() }()
+
+	deps := app.WidgetDeps{HTTP: app.NewHTTPClient(), Snapshots: st, Log: log}
+	if cfg.HistoryEnabled() {
+		deps.History = st
+	}
+	w := worker.New(app.Widgets(cfg, deps), log)
+
+	if *once {
+		if err := w.RunOnce(ctx); err != nil {
+			log.Error("worker_run_once_failed", slog.Any("err", err))
+			return 1
+		}
+		return 0
+	}
+
+	log.Info("worker_started", slog.String("db", cfg.DatabasePath))
+	if err := w.Run(ctx); err != nil {
+		log.Error("worker_failed", slog.Any("err", err))
+		return 1
+	}
+	log.Info("worker_stopped")
+	return 0
+}
@@ -4,5 +4,19 @@ go 1.25.4
 
 require (
 	github.com/a-h/templ v0.3.960
+	github.com/andybalholm/brotli v1.2.0
+	github.com/coreos/go-oidc/v3 v3.18.0
 	github.com/go-chi/chi/v5 v5.2.3
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/microcosm-cc/bluemonday v1.0.27
+	github.com/yuin/goldmark v1.8.2
+	golang.org/x/crypto v0.54.0
+	golang.org/x/oauth2 v0.36.0
+)
+
+require (
+	github.com/aymerick/douceur v0.2.0 // indirect
+	github.com/go-jose/go-jose/v4 v4.1.4 // indirect
+	github.com/gorilla/css v1.0.1 // indirect
+	golang.org/x/net v0.56.0 // indirect
 )
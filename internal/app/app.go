@@ -1,49 +1,121 @@
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/patrickneise/dashboard/internal/assets"
+	"github.com/patrickneise/dashboard/internal/auth"
 	"github.com/patrickneise/dashboard/internal/config"
-	"github.com/patrickneise/dashboard/internal/httpx"
 	"github.com/patrickneise/dashboard/internal/logging"
+	"github.com/patrickneise/dashboard/internal/prefs"
 	"github.com/patrickneise/dashboard/internal/server"
-	"github.com/patrickneise/dashboard/internal/widgetkit"
-	"github.com/patrickneise/dashboard/internal/widgets/hn"
-	"github.com/patrickneise/dashboard/internal/widgets/weather"
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/static"
 )
 
 type App struct {
 	Router http.Handler
+	Store  *store.Store
 }
 
-func Build(cfg config.Config, log *slog.Logger) (*App, error) {
-	// Shared HTTP client for all public API widgets
-	sharedHTTP := httpx.New("dashboard/0.1 (+https://github.com/patrickneise/dashboard)")
-
-	// Widget handlers
-	weatherWidget := weather.NewWidgetHandler(weather.Options{
-		Lat:    cfg.WeatherLat,
-		Lon:    cfg.WeatherLon,
-		Hours:  cfg.WeatherHours,
-		TTL:    cfg.WidgetTTL,
-		Log:    log,
-		Client: weather.NewClient(sharedHTTP),
-	})
+// Close releases resources held by the app (database handle).
+func (a *App) Close() error {
+	if a.Store == nil {
+		return nil
+	}
+	return a.Store.Close()
+}
 
-	hnWidget := hn.NewWidgetHandler(hn.Options{
-		Count:  10,
-		TTL:    cfg.WidgetTTL,
-		Log:    log,
-		Client: hn.NewClient(sharedHTTP),
+func Build(ctx context.Context, cfg config.Config, log *slog.Logger) (*App, error) {
+	// Storage
+	st, err := store.Open(ctx, cfg.DatabasePath, cfg.AutoMigrate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Auth
+	sessionKey := cfg.SessionKey
+	if len(sessionKey) == 0 {
+		log.Warn("session_key_not_set",
+			slog.String("hint", "sessions will not survive a restart; set SESSION_KEY"))
+		sessionKey = make([]byte, 32)
+		if _, err := rand.Read(sessionKey); err != nil {
+			_ = st.Close()
+			return nil, fmt.Errorf("generate session key: %w", err)
+		}
+	}
+
+	authn, err := auth.New(auth.Options{
+		Store:         st,
+		Key:           sessionKey,
+		TTL:           cfg.SessionTTL,
+		Secure:        cfg.Env == config.EnvProd,
+		AllowedGroups: cfg.AuthAllowedGroups,
+		Log:           log,
 	})
+	if err != nil {
+		_ = st.Close()
+		return nil, err
+	}
+
+	if err := authn.Bootstrap(ctx, cfg.AuthBootstrapUser, cfg.AuthBootstrapPassword); err != nil {
+		_ = st.Close()
+		return nil, fmt.Errorf("bootstrap user: %w", err)
+	}
 
-	// Registry
-	reg := widgetkit.NewRegistry()
-	reg.MustAdd(widgetkit.Spec{Key: "weather", Title: "Weather", Handler: weatherWidget})
-	reg.MustAdd(widgetkit.Spec{Key: "hn", Title: "Hacker News", Handler: hnWidget})
+	// Static assets
+	var staticFS fs.FS = static.FS
+	if cfg.StaticFromDisk {
+		staticFS = os.DirFS(cfg.StaticDir)
+	}
+	staticAssets, err := assets.New(staticFS, cfg.StaticFromDisk)
+	if err != nil {
+		_ = st.Close()
+		return nil, err
+	}
+
+	// Per-user widget preferences
+	userPrefs := prefs.NewService(st.Queries, log)
+
+	// Shared HTTP client for all public API widgets
+	sharedHTTP := NewHTTPClient()
+
+	// Optional single sign-on
+	var sso *auth.OIDC
+	if cfg.OIDCEnabled() {
+		sso, err = auth.NewOIDC(authn, auth.OIDCOptions{
+			Issuer:        cfg.OIDCIssuer,
+			ClientID:      cfg.OIDCClientID,
+			ClientSecret:  cfg.OIDCClientSecret,
+			RedirectURL:   cfg.OIDCRedirectURL,
+			Scopes:        cfg.OIDCScopes,
+			UsernameClaim: cfg.OIDCUsernameClaim,
+			GroupsClaim:   cfg.OIDCGroupsClaim,
+			HTTP:          sharedHTTP.HTTP,
+		})
+		if err != nil {
+			_ = st.Close()
+			return nil, err
+		}
+	}
+
+	// Widgets (reading worker snapshots when configured)
+	deps := WidgetDeps{HTTP: sharedHTTP, Store: st, Prefs: userPrefs, Log: log}
+	if cfg.WidgetSource == config.SourceStore {
+		deps.Snapshots = st
+	}
+	if cfg.HistoryEnabled() {
+		deps.History = st
+	}
+	reg := Widgets(cfg, deps)
 
 	// Router + middleware
 	r := chi.NewRouter()
@@ -52,10 +124,23 @@ func Build(cfg config.Config, log *slog.Logger) (*App, error) {
 	r.Use(middleware.Recoverer)
 
 	r.Use(server.SecurityHeaders)
+	r.Use(server.ContentSecurityPolicy(cfg.CSPReportOnly))
+	r.Use(staticAssets.Middleware)
 	r.Use(logging.RequestLogger(log))
+	r.Use(server.Compress(1024))
+	r.Use(server.CSRF)
+	r.Use(authn.LoadSession)
+	r.Use(userPrefs.Middleware)
 
 	// Routes
-	server.RegisterRoutes(r, reg)
+	server.RegisterRoutes(r, server.Deps{
+		Registry: reg,
+		Auth:     authn,
+		SSO:      sso,
+		Prefs:    userPrefs,
+		Assets:   staticAssets,
+		Log:      log,
+	})
 
-	return &App{Router: r}, nil
+	return &App{Router: r, Store: st}, nil
 }
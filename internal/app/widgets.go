@@ -0,0 +1,129 @@
+package app
+
+import (
+	"log/slog"
+
+	"github.com/patrickneise/dashboard/internal/config"
+	"github.com/patrickneise/dashboard/internal/httpx"
+	"github.com/patrickneise/dashboard/internal/prefs"
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+	"github.com/patrickneise/dashboard/internal/widgets/bookmarks"
+	"github.com/patrickneise/dashboard/internal/widgets/hn"
+	"github.com/patrickneise/dashboard/internal/widgets/notes"
+	"github.com/patrickneise/dashboard/internal/widgets/todo"
+	"github.com/patrickneise/dashboard/internal/widgets/weather"
+)
+
+// NewHTTPClient returns the outbound client shared by widgets and SSO.
+func NewHTTPClient() *httpx.Client {
+	return httpx.New("dashboard/0.1 (+https://github.com/patrickneise/dashboard)")
+}
+
+// WidgetDeps are the shared services widgets are built with.
+type WidgetDeps struct {
+	HTTP      *httpx.Client
+	Snapshots widgetkit.SnapshotStore // nil: always fetch live
+	History   widgetkit.HistoryStore  // nil: no history or trends
+	Store     *store.Store            // nil: store-backed widgets (bookmarks, todo, notes) can't load
+	Prefs     *prefs.Service          // nil: no weather location picker
+	Log       *slog.Logger
+}
+
+// Widgets builds the widget registry without a full Build, so the CLI and worker can use
+// it. Widgets marked Store in their Spec only load when d.Store is set.
+func Widgets(cfg config.Config, d WidgetDeps) *widgetkit.Registry {
+	weatherClient := weather.NewClient(d.HTTP)
+	nws := weather.NewNWS(d.HTTP)
+
+	var alerts weather.AlertsProvider
+	if cfg.WeatherAlerts == "nws" {
+		alerts = nws
+	}
+
+	providers := weather.Failover{Log: d.Log}
+	for _, name := range cfg.WeatherProviders {
+		switch name {
+		case "openmeteo":
+			providers.Providers = append(providers.Providers, weather.OpenMeteo{Client: weatherClient})
+		case "nws":
+			providers.Providers = append(providers.Providers, nws)
+		}
+	}
+
+	weatherWidget := weather.NewWidgetHandler(weather.Options{
+		Lat:   cfg.WeatherLat,
+		Lon:   cfg.WeatherLon,
+		Hours: cfg.WeatherHours,
+		Days:  cfg.WeatherDays,
+		Units: prefs.Units(cfg.WeatherUnits),
+
+		LocationName: cfg.WeatherLocation,
+		Language:     cfg.WeatherLanguage,
+		Prefs:        d.Prefs,
+		Alerts:       alerts,
+		Provider:     providers,
+
+		TTL:       cfg.WidgetTTL,
+		Snapshots: d.Snapshots,
+		History:   d.History,
+		Retention: cfg.HistoryRetention,
+		Log:       d.Log,
+		Client:    weatherClient,
+	})
+
+	hnLists := make([]hn.List, 0, len(cfg.HNLists))
+	for _, l := range cfg.HNLists {
+		hnLists = append(hnLists, hn.List(l))
+	}
+
+	hnWidget := hn.NewWidgetHandler(hn.Options{
+		List:  hn.List(cfg.HNList),
+		Lists: hnLists,
+		Count: 10,
+		Filter: hn.Filter{
+			Include:  cfg.HNInclude,
+			Exclude:  cfg.HNExclude,
+			Domains:  cfg.HNBlockDomains,
+			MinScore: cfg.HNMinScore,
+			MaxAge:   cfg.HNMaxAge,
+		},
+		Highlight: cfg.HNHighlight,
+		ItemTTL:   cfg.HNItemTTL,
+		Comments:  cfg.HNComments,
+		TTL:       cfg.WidgetTTL,
+		Snapshots: d.Snapshots,
+		History:   d.History,
+		Retention: cfg.HistoryRetention,
+		Log:       d.Log,
+		Client:    hn.NewClient(d.HTTP),
+	})
+
+	var queries *store.Queries
+	if d.Store != nil {
+		queries = d.Store.Queries
+	}
+
+	bookmarksWidget := bookmarks.NewWidgetHandler(bookmarks.Options{
+		Queries: queries,
+		Log:     d.Log,
+	})
+
+	todoWidget := todo.NewWidgetHandler(todo.Options{
+		Store: d.Store,
+		Log:   d.Log,
+	})
+
+	notesWidget := notes.NewWidgetHandler(notes.Options{
+		Queries: queries,
+		Log:     d.Log,
+	})
+
+	reg := widgetkit.NewRegistry()
+	reg.MustAdd(widgetkit.Spec{Key: "weather", Title: "Weather", Handler: weatherWidget, Groups: cfg.WidgetGroups["weather"]})
+	reg.MustAdd(widgetkit.Spec{Key: "hn", Title: "Hacker News", Handler: hnWidget, Groups: cfg.WidgetGroups["hn"]})
+	reg.MustAdd(widgetkit.Spec{Key: "bookmarks", Title: "Bookmarks", Handler: bookmarksWidget, Groups: cfg.WidgetGroups["bookmarks"], Store: true, PerUser: true})
+	reg.MustAdd(widgetkit.Spec{Key: "todo", Title: "Todo", Handler: todoWidget, Groups: cfg.WidgetGroups["todo"], Store: true})
+	reg.MustAdd(widgetkit.Spec{Key: "notes", Title: "Notes", Handler: notesWidget, Groups: cfg.WidgetGroups["notes"], Store: true})
+	return reg
+}
@@ -0,0 +1,224 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/patrickneise/dashboard/internal/httpx"
+)
+
+// URLPrefix is where the asset handler is mounted.
+const URLPrefix = "/static/"
+
+// file is one asset held in memory with its precompressed variants.
+type file struct {
+	name        string // logical name, e.g. "js/htmx.min.js"
+	hashed      string // e.g. "js/htmx.min.3f2a1b9c.js"
+	etag        string
+	contentType string
+
+	raw    []byte
+	gzip   []byte
+	brotli []byte
+}
+
+type Assets struct {
+	dev  bool
+	disk http.Handler
+
+	byName   map[string]*file // logical name -> file
+	byHashed map[string]*file // hashed name -> file
+	modTime  time.Time
+}
+
+// New indexes every file in fsys. In dev mode files are served straight from fsys on
+// each request (so edits show up without a rebuild) and URLs are not fingerprinted.
+func New(fsys fs.FS, dev bool) (*Assets, error) {
+	a := &Assets{
+		dev:      dev,
+		byName:   make(map[string]*file),
+		byHashed: make(map[string]*file),
+		modTime:  time.Now(),
+	}
+
+	if dev {
+		a.disk = http.FileServerFS(fsys)
+		return a, nil
+	}
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		raw, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		f, err := newFile(p, raw)
+		if err != nil {
+			return fmt.Errorf("assets: %s: %w", p, err)
+		}
+		a.byName[f.name] = f
+		a.byHashed[f.hashed] = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func newFile(name string, raw []byte) (*file, error) {
+	sum := sha256.Sum256(raw)
+	hash := hex.EncodeToString(sum[:])[:12]
+
+	ext := path.Ext(name)
+	ct := mime.TypeByExtension(ext)
+	if ct == "" {
+		ct = http.DetectContentType(raw)
+	}
+
+	f := &file{
+		name:        name,
+		hashed:      strings.TrimSuffix(name, ext) + "." + hash + ext,
+		etag:        `"` + hash + `"`,
+		contentType: ct,
+		raw:         raw,
+	}
+
+	if httpx.Compressible(ct) && len(raw) > 1024 {
+		var gz bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+		if _, err := zw.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		var br bytes.Buffer
+		bw := brotli.NewWriterLevel(&br, brotli.BestCompression)
+		if _, err := bw.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := bw.Close(); err != nil {
+			return nil, err
+		}
+
+		// Only keep variants that actually save bytes.
+		if gz.Len() < len(raw) {
+			f.gzip = gz.Bytes()
+		}
+		if br.Len() < len(raw) {
+			f.brotli = br.Bytes()
+		}
+	}
+
+	return f, nil
+}
+
+// Path returns the URL for a logical asset name such as "js/htmx.min.js". In prod the
+// URL carries a content hash so it can be cached forever.
+func (a *Assets) Path(name string) string {
+	if !a.dev {
+		if f, ok := a.byName[name]; ok {
+			return URLPrefix + f.hashed
+		}
+	}
+	return URLPrefix + name
+}
+
+// Handler serves assets; mount it with http.StripPrefix(URLPrefix, ...).
+func (a *Assets) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.dev {
+			w.Header().Set("Cache-Control", "no-cache")
+			a.disk.ServeHTTP(w, r)
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		f, immutable := a.byHashed[name]
+		if !immutable {
+			// Unfingerprinted names still work, but must be revalidated.
+			f = a.byName[name]
+		}
+		if f == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("Content-Type", f.contentType)
+		h.Set("Vary", "Accept-Encoding")
+		if immutable {
+			h.Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			h.Set("Cache-Control", "no-cache")
+		}
+
+		body, etag := f.raw, f.etag
+		switch enc := httpx.PreferredEncoding(r.Header.Get("Accept-Encoding"), availableEncodings(f)...); enc {
+		case "br", "gzip":
+			if enc == "br" {
+				body = f.brotli
+			} else {
+				body = f.gzip
+			}
+			h.Set("Content-Encoding", enc)
+			// Each encoding is a distinct representation, so give it its own strong ETag.
+			etag = strings.TrimSuffix(f.etag, `"`) + "-" + enc + `"`
+		}
+		h.Set("ETag", etag)
+
+		// ServeContent handles If-None-Match / If-Modified-Since and Range requests.
+		http.ServeContent(w, r, "", a.modTime, bytes.NewReader(body))
+	})
+}
+
+func availableEncodings(f *file) []string {
+	var out []string
+	if f.brotli != nil {
+		out = append(out, "br")
+	}
+	if f.gzip != nil {
+		out = append(out, "gzip")
+	}
+	return out
+}
+
+type ctxKey struct{}
+
+// Middleware makes a available to templates through the request context.
+func (a *Assets) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey{}, a)))
+	})
+}
+
+// URL resolves an asset URL using the Assets attached to ctx, falling back to the plain
+// path when none is attached.
+func URL(ctx context.Context, name string) string {
+	if a, ok := ctx.Value(ctxKey{}).(*Assets); ok {
+		return a.Path(name)
+	}
+	return URLPrefix + name
+}
@@ -0,0 +1,3 @@
+// Package assets serves the static files (embedded or from disk in dev) with
+// content-hashed URLs, immutable caching, and precompressed gzip/brotli variants.
+package assets
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// User is the authenticated principal attached to a request.
+type User struct {
+	ID       int64
+	Username string
+	Groups   []string
+}
+
+// InAnyGroup reports whether u belongs to at least one of groups. An empty groups list
+// means "no restriction" and always matches.
+func (u User) InAnyGroup(groups []string) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	for _, want := range groups {
+		if slices.Contains(u.Groups, want) {
+			return true
+		}
+	}
+	return false
+}
+
+type Options struct {
+	Store *store.Store
+
+	// Key is the 32-byte AES key used to seal session cookies.
+	Key []byte
+	TTL time.Duration
+
+	// Secure marks the session cookie Secure (set in prod, behind TLS).
+	Secure bool
+
+	// AllowedGroups, when non-empty, limits the dashboard to members of these groups.
+	AllowedGroups []string
+
+	Log *slog.Logger
+}
+
+type Auth struct {
+	st      *store.Store
+	q       *store.Queries
+	sealer  *sealer
+	ttl     time.Duration
+	secure  bool
+	allowed []string
+	log     *slog.Logger
+}
+
+func New(opts Options) (*Auth, error) {
+	if opts.Store == nil {
+		return nil, errors.New("auth: store is required")
+	}
+
+	s, err := newSealer(opts.Key)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+
+	return &Auth{
+		st:      opts.Store,
+		q:       opts.Store.Queries,
+		sealer:  s,
+		ttl:     ttl,
+		secure:  opts.Secure,
+		allowed: opts.AllowedGroups,
+		log:     opts.Log,
+	}, nil
+}
+
+// Authenticate checks a username/password pair against the users table.
+func (a *Auth) Authenticate(ctx context.Context, username, password string) (User, error) {
+	username = strings.TrimSpace(username)
+
+	row, err := a.q.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Burn the same bcrypt work as a real check.
+			_ = checkPassword(string(dummyHash), password)
+			return User{}, ErrInvalidCredentials
+		}
+		return User{}, err
+	}
+
+	if row.PasswordHash == "" || !checkPassword(row.PasswordHash, password) {
+		return User{}, ErrInvalidCredentials
+	}
+
+	if err := a.q.TouchUserLogin(ctx, row.ID); err != nil && a.log != nil {
+		a.log.Warn("auth_touch_login_failed", slog.Int64("user_id", row.ID), slog.Any("err", err))
+	}
+
+	return a.loadUser(ctx, row)
+}
+
+// loadUser converts a users row into a User, including group memberships.
+func (a *Auth) loadUser(ctx context.Context, row store.User) (User, error) {
+	groups, err := a.q.ListUserGroups(ctx, row.ID)
+	if err != nil {
+		return User{}, fmt.Errorf("auth: list groups for user %d: %w", row.ID, err)
+	}
+	return User{ID: row.ID, Username: row.Username, Groups: groups}, nil
+}
+
+// CreateUser adds a local account with a bcrypt-hashed password.
+func (a *Auth) CreateUser(ctx context.Context, username, password string) (User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return User{}, errors.New("auth: username is required")
+	}
+	if len(password) < 8 {
+		return User{}, errors.New("auth: password must be at least 8 characters")
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return User{}, err
+	}
+
+	row, err := a.q.CreateUser(ctx, store.CreateUserParams{Username: username, PasswordHash: hash})
+	if err != nil {
+		return User{}, fmt.Errorf("auth: create user %q: %w", username, err)
+	}
+	return User{ID: row.ID, Username: row.Username}, nil
+}
+
+// Bootstrap creates the first account when the users table is empty, so a fresh
+// deployment isn't locked out. It is a no-op once any user exists.
+func (a *Auth) Bootstrap(ctx context.Context, username, password string) error {
+	n, err := a.q.CountUsers(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	if username == "" || password == "" {
+		if a.log != nil {
+			a.log.Warn("auth_no_users",
+				slog.String("hint", "set AUTH_BOOTSTRAP_USER and AUTH_BOOTSTRAP_PASSWORD to create the first account"))
+		}
+		return nil
+	}
+
+	u, err := a.CreateUser(ctx, username, password)
+	if err != nil {
+		return err
+	}
+	if a.log != nil {
+		a.log.Info("auth_bootstrap_user_created", slog.String("username", u.Username))
+	}
+	return nil
+}
+
+// StartSession issues a fresh session cookie for u.
+func (a *Auth) StartSession(w http.ResponseWriter, u User) error {
+	now := time.Now()
+	value, err := a.sealer.encode(Session{
+		UserID:    u.ID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(a.ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   a.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// EndSession clears the session cookie.
+func (a *Auth) EndSession(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   a.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,3 @@
+// Package auth implements local user accounts, encrypted session cookies, and the
+// middleware that protects dashboard and widget routes.
+package auth
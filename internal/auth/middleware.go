@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type ctxKey struct{}
+
+// WithUser returns a copy of ctx carrying u.
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, ctxKey{}, u)
+}
+
+// UserFromContext returns the user attached by LoadSession, if any.
+func UserFromContext(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(ctxKey{}).(User)
+	return u, ok
+}
+
+// LoadSession decodes the session cookie (if present) and attaches the user to the
+// request context. It never rejects a request; use RequireUser for that.
+func (a *Auth) LoadSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(SessionCookieName)
+		if err != nil || c.Value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var u User
+		now := time.Now()
+		sess, err := a.sealer.decode(c.Value, now)
+		if err != nil {
+			a.EndSession(w)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Look the user up on every request so deleted accounts lose access immediately.
+		row, err := a.q.GetUserByID(r.Context(), sess.UserID)
+		if err == nil {
+			u, err = a.loadUser(r.Context(), row)
+		}
+		if err != nil {
+			if a.log != nil {
+				a.log.Warn("auth_session_user_lookup_failed",
+					slog.Int64("user_id", sess.UserID), slog.Any("err", err))
+			}
+			a.EndSession(w)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Sliding expiry: re-issue once the session is past half its lifetime.
+		if now.Sub(sess.IssuedAt) > a.ttl/2 {
+			if err := a.StartSession(w, u); err != nil && a.log != nil {
+				a.log.Warn("auth_session_refresh_failed", slog.Any("err", err))
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), u)))
+	})
+}
+
+// RequireUser rejects requests without a session. Full page loads are redirected to the
+// login page; HTMX requests get a 401 with HX-Redirect so the whole page navigates; API
+// clients (/api/ paths or Accept: application/json) get a plain 401. Signed-in users outside
+// Options.AllowedGroups get a 403.
+func (a *Auth) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, ok := UserFromContext(r.Context()); ok {
+			if !u.InAnyGroup(a.allowed) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		login := LoginURL(r.URL.RequestURI())
+		if r.Header.Get("HX-Request") == "true" {
+			// Send the browser back to the page it was on, not the fragment URL.
+			if cur := r.Header.Get("HX-Current-URL"); cur != "" {
+				if u, err := url.Parse(cur); err == nil {
+					login = LoginURL(u.RequestURI())
+				}
+			}
+			w.Header().Set("HX-Redirect", login)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		accept := r.Header.Get("Accept")
+		apiClient := strings.HasPrefix(r.URL.Path, "/api/") ||
+			(strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html"))
+
+		if apiClient || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		http.Redirect(w, r, login, http.StatusSeeOther)
+	})
+}
+
+// LoginURL builds the login page URL that returns to next after signing in.
+func LoginURL(next string) string {
+	if next == "" || next == "/" {
+		return "/login"
+	}
+	return "/login?next=" + url.QueryEscape(next)
+}
+
+// SafeNext returns next if it is a local absolute path, or "/" otherwise, to avoid open
+// redirects through the login form.
+func SafeNext(next string) string {
+	if next == "" || next[0] != '/' || (len(next) > 1 && (next[1] == '/' || next[1] == '\\')) {
+		return "/"
+	}
+	return next
+}
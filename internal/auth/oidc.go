@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+const (
+	oidcFlowCookieName = "dashboard_oidc"
+	oidcFlowTTL        = 10 * time.Minute
+)
+
+var ErrUsernameTaken = errors.New("username already belongs to a local account")
+
+type OIDCOptions struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Claim names used to map the ID token onto a local user.
+	UsernameClaim string // default "preferred_username", falls back to "email"
+	GroupsClaim   string // default "groups"
+
+	// HTTP is used for discovery, JWKS, and token exchange.
+	HTTP *http.Client
+}
+
+// OIDC implements the OpenID Connect authorization-code flow with PKCE and maps verified
+// ID tokens onto local users and groups.
+type OIDC struct {
+	auth *Auth
+	opts OIDCOptions
+
+	// Discovery is done lazily so an unreachable IdP doesn't block startup.
+	mu       sync.Mutex
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+}
+
+// oidcFlow is the state kept in a short-lived sealed cookie between redirect and callback.
+type oidcFlow struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"cv"`
+	Next         string    `json:"next"`
+	ExpiresAt    time.Time `json:"exp"`
+}
+
+func NewOIDC(a *Auth, opts OIDCOptions) (*OIDC, error) {
+	if a == nil {
+		return nil, errors.New("auth: oidc requires auth")
+	}
+	if opts.Issuer == "" || opts.ClientID == "" || opts.RedirectURL == "" {
+		return nil, errors.New("auth: oidc issuer, client id and redirect url are required")
+	}
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	if opts.UsernameClaim == "" {
+		opts.UsernameClaim = "preferred_username"
+	}
+	if opts.GroupsClaim == "" {
+		opts.GroupsClaim = "groups"
+	}
+	if opts.HTTP == nil {
+		opts.HTTP = http.DefaultClient
+	}
+	return &OIDC{auth: a, opts: opts}, nil
+}
+
+func (o *OIDC) discover(ctx context.Context) (*oidc.Provider, *oidc.IDTokenVerifier, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider != nil {
+		return o.provider, o.verifier, nil
+	}
+
+	p, err := oidc.NewProvider(oidc.ClientContext(ctx, o.opts.HTTP), o.opts.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	o.provider = p
+	o.verifier = p.Verifier(&oidc.Config{ClientID: o.opts.ClientID})
+	return o.provider, o.verifier, nil
+}
+
+func (o *OIDC) oauthConfig(p *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.opts.ClientID,
+		ClientSecret: o.opts.ClientSecret,
+		RedirectURL:  o.opts.RedirectURL,
+		Endpoint:     p.Endpoint(),
+		Scopes:       o.opts.Scopes,
+	}
+}
+
+// Begin stores the flow state in a cookie and redirects the browser to the IdP.
+func (o *OIDC) Begin(w http.ResponseWriter, r *http.Request, next string) error {
+	p, _, err := o.discover(r.Context())
+	if err != nil {
+		return err
+	}
+
+	flow := oidcFlow{
+		State:        randomToken(),
+		Nonce:        randomToken(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Next:         SafeNext(next),
+		ExpiresAt:    time.Now().Add(oidcFlowTTL),
+	}
+
+	value, err := o.auth.sealer.seal(oidcFlowCookieName, flow)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookieName,
+		Value:    value,
+		Path:     "/auth/oidc",
+		MaxAge:   int(oidcFlowTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   o.auth.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	url := o.oauthConfig(p).AuthCodeURL(flow.State,
+		oidc.Nonce(flow.Nonce),
+		oauth2.S256ChallengeOption(flow.CodeVerifier),
+	)
+	http.Redirect(w, r, url, http.StatusFound)
+	return nil
+}
+
+// Complete handles the IdP callback: it checks state, exchanges the code (with the PKCE
+// verifier), verifies the ID token against the provider's JWKS, and returns the mapped
+// local user plus the path to continue to.
+func (o *OIDC) Complete(w http.ResponseWriter, r *http.Request) (User, string, error) {
+	ctx := r.Context()
+
+	c, err := r.Cookie(oidcFlowCookieName)
+	if err != nil {
+		return User{}, "", errors.New("oidc: missing login state (cookie expired?)")
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcFlowCookieName, Path: "/auth/oidc", MaxAge: -1})
+
+	var flow oidcFlow
+	if err := o.auth.sealer.open(oidcFlowCookieName, c.Value, &flow); err != nil {
+		return User{}, "", errors.New("oidc: invalid login state")
+	}
+	if time.Now().After(flow.ExpiresAt) {
+		return User{}, "", errors.New("oidc: login state expired")
+	}
+
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return User{}, "", fmt.Errorf("oidc: provider returned %s: %s", e, q.Get("error_description"))
+	}
+	if q.Get("state") == "" || q.Get("state") != flow.State {
+		return User{}, "", errors.New("oidc: state mismatch")
+	}
+
+	p, verifier, err := o.discover(ctx)
+	if err != nil {
+		return User{}, "", err
+	}
+
+	tok, err := o.oauthConfig(p).Exchange(oidc.ClientContext(ctx, o.opts.HTTP), q.Get("code"),
+		oauth2.VerifierOption(flow.CodeVerifier))
+	if err != nil {
+		return User{}, "", fmt.Errorf("oidc: code exchange: %w", err)
+	}
+
+	rawID, ok := tok.Extra("id_token").(string)
+	if !ok || rawID == "" {
+		return User{}, "", errors.New("oidc: token response has no id_token")
+	}
+
+	idt, err := verifier.Verify(ctx, rawID)
+	if err != nil {
+		return User{}, "", fmt.Errorf("oidc: verify id token: %w", err)
+	}
+	if idt.Nonce != flow.Nonce {
+		return User{}, "", errors.New("oidc: nonce mismatch")
+	}
+
+	var claims map[string]any
+	if err := idt.Claims(&claims); err != nil {
+		return User{}, "", fmt.Errorf("oidc: decode claims: %w", err)
+	}
+
+	u, err := o.upsertUser(ctx, idt.Issuer, idt.Subject, o.username(claims), o.groups(claims))
+	if err != nil {
+		return User{}, "", err
+	}
+	if o.auth.log != nil {
+		o.auth.log.Info("oidc_login",
+			slog.String("username", u.Username),
+			slog.String("subject", idt.Subject),
+			slog.Any("groups", u.Groups))
+	}
+	return u, flow.Next, nil
+}
+
+// upsertUser links (issuer, subject) to a local user, creating it on first login, and
+// replaces the user's groups with the ones asserted by the IdP.
+func (o *OIDC) upsertUser(ctx context.Context, issuer, subject, username string, groups []string) (User, error) {
+	var row store.User
+
+	err := o.auth.st.InTx(ctx, func(q *store.Queries) error {
+		var err error
+		row, err = q.GetUserByIdentity(ctx, store.GetUserByIdentityParams{Issuer: issuer, Subject: subject})
+		switch {
+		case err == nil:
+		case errors.Is(err, sql.ErrNoRows):
+			if username == "" {
+				return errors.New("oidc: id token has no usable username claim")
+			}
+			// Never silently take over a password account with the same name.
+			if _, err := q.GetUserByUsername(ctx, username); err == nil {
+				return ErrUsernameTaken
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			row, err = q.CreateUser(ctx, store.CreateUserParams{Username: username})
+			if err != nil {
+				return err
+			}
+			if err := q.CreateUserIdentity(ctx, store.CreateUserIdentityParams{
+				Issuer: issuer, Subject: subject, UserID: row.ID,
+			}); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if err := q.DeleteUserGroups(ctx, row.ID); err != nil {
+			return err
+		}
+		for _, g := range groups {
+			if err := q.AddUserGroup(ctx, store.AddUserGroupParams{UserID: row.ID, GroupName: g}); err != nil {
+				return err
+			}
+		}
+		return q.TouchUserLogin(ctx, row.ID)
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return o.auth.loadUser(ctx, row)
+}
+
+func (o *OIDC) username(claims map[string]any) string {
+	for _, name := range []string{o.opts.UsernameClaim, "email"} {
+		if s, ok := claims[name].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// groups accepts either a JSON array of strings or a single space/comma separated string.
+func (o *OIDC) groups(claims map[string]any) []string {
+	var out []string
+	switch v := claims[o.opts.GroupsClaim].(type) {
+	case []any:
+		for _, g := range v {
+			if s, ok := g.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+	case string:
+		out = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return out
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
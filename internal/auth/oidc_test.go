@@ -0,0 +1,459 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+const testClientID = "dashboard"
+
+// fakeIdP is an in-process OpenID provider: discovery, JWKS and a token endpoint that
+// checks the PKCE verifier against the challenge the authorization request carried.
+type fakeIdP struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant // by authorization code
+	tokens int              // token requests served
+}
+
+// grant is what the IdP remembers between authorization and token exchange.
+type grant struct {
+	challenge string
+	nonce     string
+	claims    map[string]any
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key, grants: make(map[string]grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("GET /jwks", idp.jwks)
+	mux.HandleFunc("POST /token", idp.token)
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func (idp *fakeIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	base := idp.srv.URL
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                base,
+		"authorization_endpoint":                base + "/authorize",
+		"token_endpoint":                        base + "/token",
+		"jwks_uri":                              base + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *fakeIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := idp.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   b64(pub.N.Bytes()),
+			"e":   b64(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	idp.tokens++
+	g, ok := idp.grants[r.PostFormValue("code")]
+	delete(idp.grants, r.PostFormValue("code"))
+	idp.mu.Unlock()
+
+	if r.PostFormValue("grant_type") != "authorization_code" || !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if b64(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid_grant", "error_description": "PKCE verification failed",
+		})
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   idp.srv.URL,
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": g.nonce,
+	}
+	for k, v := range g.claims {
+		claims[k] = v
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idp.sign(claims),
+	})
+}
+
+// sign returns claims as an RS256 JWT.
+func (idp *fakeIdP) sign(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signing := b64(header) + "." + b64(payload)
+	sum := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, sum[:])
+	if err != nil {
+		panic(err)
+	}
+	return signing + "." + b64(sig)
+}
+
+// authorize plays the user approving the request at the IdP: it records a grant for
+// the request's PKCE challenge and returns the callback query. A non-empty nonce
+// replaces the request's, to simulate a replayed ID token.
+func (idp *fakeIdP) authorize(t *testing.T, authURL *url.URL, claims map[string]any, nonce string) url.Values {
+	t.Helper()
+	q := authURL.Query()
+	if nonce == "" {
+		nonce = q.Get("nonce")
+	}
+	code := randomToken()
+
+	idp.mu.Lock()
+	idp.grants[code] = grant{challenge: q.Get("code_challenge"), nonce: nonce, claims: claims}
+	idp.mu.Unlock()
+
+	return url.Values{"code": {code}, "state": {q.Get("state")}}
+}
+
+func (idp *fakeIdP) tokenRequests() int {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return idp.tokens
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// newTestOIDC wires Auth (on a fresh migrated database) to the fake IdP.
+func newTestOIDC(t *testing.T, idp *fakeIdP, allowed []string) (*Auth, *OIDC) {
+	t.Helper()
+	ctx := context.Background()
+
+	st, err := store.Open(ctx, filepath.Join(t.TempDir(), "auth.db"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	a, err := New(Options{Store: st, Key: key, AllowedGroups: allowed})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := NewOIDC(a, OIDCOptions{
+		Issuer:      idp.srv.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://dashboard.test/auth/oidc/callback",
+		HTTP:        idp.srv.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, o
+}
+
+// begin starts a login and returns the IdP authorization URL and the flow cookie.
+func begin(t *testing.T, o *OIDC, next string) (*url.URL, *http.Cookie) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil)
+	if err := o.Begin(rec, req, next); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Begin status = %d, want 302", rec.Code)
+	}
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == oidcFlowCookieName {
+			return loc, c
+		}
+	}
+	t.Fatal("Begin set no flow cookie")
+	return nil, nil
+}
+
+// complete calls back with query and the flow cookie.
+func complete(o *OIDC, cookie *http.Cookie, query url.Values) (User, string, error) {
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+query.Encode(), nil)
+	req.AddCookie(cookie)
+	return o.Complete(httptest.NewRecorder(), req)
+}
+
+// login runs the whole flow with the IdP asserting claims.
+func login(t *testing.T, idp *fakeIdP, o *OIDC, claims map[string]any) (User, string, error) {
+	t.Helper()
+	authURL, cookie := begin(t, o, "/settings")
+	return complete(o, cookie, idp.authorize(t, authURL, claims, ""))
+}
+
+func TestOIDCBeginUsesPKCE(t *testing.T) {
+	idp := newFakeIdP(t)
+	_, o := newTestOIDC(t, idp, nil)
+
+	authURL, cookie := begin(t, o, "/settings")
+	q := authURL.Query()
+
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != idp.srv.URL+"/authorize" {
+		t.Errorf("redirected to %s, want the IdP authorization endpoint", got)
+	}
+	for param, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          "http://dashboard.test/auth/oidc/callback",
+		"code_challenge_method": "S256",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	if !strings.Contains(q.Get("scope"), "openid") {
+		t.Errorf("scope = %q, want openid", q.Get("scope"))
+	}
+
+	// The challenge is S256 of the verifier kept in the sealed cookie.
+	var flow oidcFlow
+	if err := o.auth.sealer.open(oidcFlowCookieName, cookie.Value, &flow); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(flow.CodeVerifier))
+	if q.Get("code_challenge") != b64(sum[:]) {
+		t.Error("code_challenge is not the S256 hash of the flow's verifier")
+	}
+	if q.Get("state") != flow.State || q.Get("nonce") != flow.Nonce {
+		t.Error("state/nonce in the authorization URL don't match the flow cookie")
+	}
+	if !cookie.HttpOnly || cookie.Path != "/auth/oidc" {
+		t.Errorf("flow cookie = %+v, want HttpOnly scoped to /auth/oidc", cookie)
+	}
+}
+
+func TestOIDCCompleteCreatesUser(t *testing.T) {
+	idp := newFakeIdP(t)
+	_, o := newTestOIDC(t, idp, nil)
+
+	u, next, err := login(t, idp, o, map[string]any{
+		"sub":                "sub-alice",
+		"preferred_username": "alice",
+		"groups":             []string{"dash-users", "ops"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID == 0 || u.Username != "alice" {
+		t.Errorf("user = %+v, want a new user alice", u)
+	}
+	if want := []string{"dash-users", "ops"}; !reflect.DeepEqual(u.Groups, want) {
+		t.Errorf("groups = %v, want %v", u.Groups, want)
+	}
+	if next != "/settings" {
+		t.Errorf("next = %q, want /settings", next)
+	}
+}
+
+func TestOIDCCompleteRejectsBadCallbacks(t *testing.T) {
+	claims := map[string]any{"sub": "sub-alice", "preferred_username": "alice"}
+
+	t.Run("state mismatch", func(t *testing.T) {
+		idp := newFakeIdP(t)
+		_, o := newTestOIDC(t, idp, nil)
+		authURL, cookie := begin(t, o, "/")
+		query := idp.authorize(t, authURL, claims, "")
+		query.Set("state", "forged")
+
+		if _, _, err := complete(o, cookie, query); err == nil || !strings.Contains(err.Error(), "state mismatch") {
+			t.Fatalf("err = %v, want state mismatch", err)
+		}
+		if n := idp.tokenRequests(); n != 0 {
+			t.Errorf("code was exchanged %d times despite the bad state", n)
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		idp := newFakeIdP(t)
+		_, o := newTestOIDC(t, idp, nil)
+		authURL, cookie := begin(t, o, "/")
+		query := idp.authorize(t, authURL, claims, "replayed-nonce")
+
+		if _, _, err := complete(o, cookie, query); err == nil || !strings.Contains(err.Error(), "nonce mismatch") {
+			t.Fatalf("err = %v, want nonce mismatch", err)
+		}
+	})
+
+	t.Run("wrong PKCE verifier", func(t *testing.T) {
+		idp := newFakeIdP(t)
+		_, o := newTestOIDC(t, idp, nil)
+
+		// The code was issued to a different login attempt (another flow's challenge).
+		authURL, _ := begin(t, o, "/")
+		query := idp.authorize(t, authURL, claims, "")
+		_, cookie := begin(t, o, "/")
+		var flow oidcFlow
+		if err := o.auth.sealer.open(oidcFlowCookieName, cookie.Value, &flow); err != nil {
+			t.Fatal(err)
+		}
+		query.Set("state", flow.State)
+
+		if _, _, err := complete(o, cookie, query); err == nil || !strings.Contains(err.Error(), "code exchange") {
+			t.Fatalf("err = %v, want a failed code exchange", err)
+		}
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		idp := newFakeIdP(t)
+		_, o := newTestOIDC(t, idp, nil)
+		req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?code=x&state=y", nil)
+		if _, _, err := o.Complete(httptest.NewRecorder(), req); err == nil {
+			t.Fatal("Complete succeeded without the flow cookie")
+		}
+	})
+}
+
+func TestOIDCGroupsMapToAccessRules(t *testing.T) {
+	idp := newFakeIdP(t)
+	a, o := newTestOIDC(t, idp, []string{"dash-users"})
+
+	protected := a.RequireUser(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	status := func(u User) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		protected.ServeHTTP(rec, req.WithContext(WithUser(req.Context(), u)))
+		return rec.Code
+	}
+
+	tests := []struct {
+		name   string
+		groups any
+		want   []string
+		status int
+	}{
+		{"array claim", []string{"ops", "dash-users"}, []string{"dash-users", "ops"}, http.StatusOK},
+		{"string claim", "dash-users, ops", []string{"dash-users", "ops"}, http.StatusOK},
+		{"other group", []string{"ops"}, []string{"ops"}, http.StatusForbidden},
+		{"no groups", nil, nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]any{"sub": "sub-" + tt.name, "preferred_username": tt.name}
+			if tt.groups != nil {
+				claims["groups"] = tt.groups
+			}
+			u, _, err := login(t, idp, o, claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(u.Groups, tt.want) {
+				t.Errorf("groups = %v, want %v", u.Groups, tt.want)
+			}
+			if got := status(u); got != tt.status {
+				t.Errorf("RequireUser status = %d, want %d", got, tt.status)
+			}
+		})
+	}
+}
+
+func TestOIDCRepeatLogin(t *testing.T) {
+	idp := newFakeIdP(t)
+	a, o := newTestOIDC(t, idp, nil)
+
+	first, _, err := login(t, idp, o, map[string]any{
+		"sub": "sub-alice", "preferred_username": "alice", "groups": []string{"dash-users", "ops"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same subject again: the linked user is reused (a renamed claim doesn't rename
+	// it) and the IdP's groups replace the old ones.
+	second, _, err := login(t, idp, o, map[string]any{
+		"sub": "sub-alice", "preferred_username": "alice.smith", "groups": []string{"ops"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || second.Username != "alice" {
+		t.Errorf("second login = %+v, want user %d alice", second, first.ID)
+	}
+	if want := []string{"ops"}; !reflect.DeepEqual(second.Groups, want) {
+		t.Errorf("groups after second login = %v, want %v", second.Groups, want)
+	}
+
+	// A new subject falls back to the email claim for its username.
+	carol, _, err := login(t, idp, o, map[string]any{"sub": "sub-carol", "email": "carol@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if carol.ID == first.ID || carol.Username != "carol@example.com" {
+		t.Errorf("new subject = %+v, want a separate user carol@example.com", carol)
+	}
+
+	// A new subject never takes over a local password account of the same name.
+	if _, err := a.CreateUser(context.Background(), "bob", "correct horse battery"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := login(t, idp, o, map[string]any{"sub": "sub-bob", "preferred_username": "bob"}); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("err = %v, want ErrUsernameTaken", err)
+	}
+
+	// Nor can one be created without a username.
+	if _, _, err := login(t, idp, o, map[string]any{"sub": "sub-anon"}); err == nil {
+		t.Error("login without a username claim succeeded")
+	}
+}
@@ -0,0 +1,22 @@
+package auth
+
+import "golang.org/x/crypto/bcrypt"
+
+const bcryptCost = 12
+
+// dummyHash is compared against when a username doesn't exist so failed logins take
+// roughly the same time whether or not the account is real.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dashboard-dummy-password"), bcryptCost)
+
+// HashPassword returns a bcrypt hash suitable for storing in users.password_hash.
+func HashPassword(password string) (string, error) {
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(h), nil
+}
+
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
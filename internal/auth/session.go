@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const SessionCookieName = "dashboard_session"
+
+var errInvalidSession = errors.New("invalid session")
+
+// Session is the payload sealed into the session cookie.
+type Session struct {
+	UserID    int64     `json:"uid"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// sealer encrypts and authenticates cookie values with AES-256-GCM.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts v as JSON. name is bound as additional data so a value sealed for one
+// cookie can't be replayed as another.
+func (s *sealer) seal(name string, v any) (string, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plain)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, plain, []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// open reverses seal into out.
+func (s *sealer) open(name, value string, out any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return errInvalidSession
+	}
+
+	nonce, sealed := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, sealed, []byte(name))
+	if err != nil {
+		return errInvalidSession
+	}
+
+	if err := json.Unmarshal(plain, out); err != nil {
+		return errInvalidSession
+	}
+	return nil
+}
+
+func (s *sealer) encode(sess Session) (string, error) {
+	return s.seal(SessionCookieName, sess)
+}
+
+func (s *sealer) decode(value string, now time.Time) (Session, error) {
+	var sess Session
+	if err := s.open(SessionCookieName, value, &sess); err != nil {
+		return sess, err
+	}
+	if sess.UserID == 0 || now.After(sess.ExpiresAt) {
+		return sess, errInvalidSession
+	}
+	return sess, nil
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Keyed is a set of TTL entries addressed by key, for values that vary by options
+// (e.g. per-user widget settings). The zero value is ready to use.
+type Keyed[T any] struct {
+	// MaxEntries bounds the number of keys kept. When exceeded, the least recently
+	// used entries are dropped; expired ones are kept like any other, since callers
+	// serve them as a stale fallback. Zero means 256.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // values are *keyedEntry[T]
+	lru     list.List                // most recently used first
+}
+
+type keyedEntry[T any] struct {
+	key string
+	ttl *TTL[T]
+}
+
+func (c *Keyed[T]) Get(key string, now time.Time) (T, time.Time, State) {
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		var zero T
+		return zero, time.Time{}, Miss
+	}
+	return el.Value.(*keyedEntry[T]).ttl.Get(now)
+}
+
+func (c *Keyed[T]) Set(key string, v T, exp time.Time) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+	el, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(el)
+	} else {
+		c.evictLocked()
+		el = c.lru.PushFront(&keyedEntry[T]{key: key, ttl: &TTL[T]{}})
+		c.entries[key] = el
+	}
+	c.mu.Unlock()
+
+	el.Value.(*keyedEntry[T]).ttl.Set(v, exp)
+}
+
+// Delete drops the entry for key.
+func (c *Keyed[T]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+// Clear drops every entry.
+func (c *Keyed[T]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.lru.Init()
+}
+
+// evictLocked makes room for one more entry by dropping the least recently used.
+func (c *Keyed[T]) evictLocked() {
+	limit := c.MaxEntries
+	if limit <= 0 {
+		limit = 256
+	}
+	for len(c.entries) >= limit {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*keyedEntry[T]).key)
+	}
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,6 +18,17 @@ const (
 	EnvProd Env = "prod"
 )
 
+// Source selects where serve gets widget data.
+type Source string
+
+const (
+	// SourceLive fetches upstream APIs in-process (single binary deployments).
+	SourceLive Source = "live"
+	// SourceStore reads snapshots written by `dashboard worker`, fetching live only when
+	// a snapshot is missing or expired.
+	SourceStore Source = "store"
+)
+
 type Config struct {
 	Env  Env
 	Addr string
@@ -22,9 +37,94 @@ type Config struct {
 	WeatherLat   float64
 	WeatherLon   float64
 	WeatherHours int
+	WeatherDays  int
+
+	// WeatherUnits is imperial or metric. WeatherLocation names the default
+	// coordinates (empty: reverse geocoded, in WeatherLanguage).
+	WeatherUnits    string
+	WeatherLocation string
+	WeatherLanguage string
+
+	// WeatherProviders are the forecast providers (openmeteo, nws) in failover order.
+	// WeatherAlerts is the severe weather alerts provider: nws or off.
+	WeatherProviders []string
+	WeatherAlerts    string
+
+	// HNList is the Hacker News list shown by default; HNLists are the tabs offered
+	// (top, new, best, ask, show, jobs).
+	HNList  string
+	HNLists []string
+
+	// HN story filters (see hn.Filter): keywords matched as whole words in titles,
+	// blocked domains include their subdomains, zero score/age means no limit.
+	// HNHighlight marks stories mentioning watched keywords.
+	HNInclude      []string
+	HNExclude      []string
+	HNBlockDomains []string
+	HNMinScore     int
+	HNMaxAge       time.Duration
+	HNHighlight    []string
+
+	// HNItemTTL is how long fetched HN stories (and comment previews) are reused across
+	// refreshes (zero: the widget's default). HNComments is how many top-level comments
+	// a preview shows.
+	HNItemTTL  time.Duration
+	HNComments int
 
 	// Widget caching defaults (v0)
 	WidgetTTL time.Duration
+
+	// WidgetSource is live or store (see Source).
+	WidgetSource Source
+
+	// HistoryRetention is how long fetched widget values are kept for trends. Zero
+	// disables history.
+	HistoryRetention time.Duration
+
+	// Static assets: embedded by default; StaticFromDisk serves StaticDir instead (dev).
+	StaticDir      string
+	StaticFromDisk bool
+
+	// CSPReportOnly sends the Content-Security-Policy as report-only (nothing blocked).
+	CSPReportOnly bool
+
+	// SQLite database file. AutoMigrate applies pending migrations on startup; turn it
+	// off to run `dashboard migrate up` as a separate step.
+	DatabasePath string
+	AutoMigrate  bool
+
+	// Auth: SessionKey seals session cookies (32 bytes, base64 in SESSION_KEY).
+	// Empty in dev means a random per-process key.
+	SessionKey []byte
+	SessionTTL time.Duration
+
+	// Optional first account, created only when no users exist yet.
+	AuthBootstrapUser     string
+	AuthBootstrapPassword string
+
+	// Access rules: AuthAllowedGroups gates the whole dashboard; WidgetGroups maps a
+	// widget key to the groups allowed to see it. Empty means everyone.
+	AuthAllowedGroups []string
+	WidgetGroups      map[string][]string
+
+	// OIDC single sign-on (enabled when OIDCIssuer is set)
+	OIDCIssuer        string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        []string
+	OIDCUsernameClaim string
+	OIDCGroupsClaim   string
+}
+
+// HistoryEnabled reports whether widget values are recorded for trends.
+func (c Config) HistoryEnabled() bool {
+	return c.HistoryRetention > 0
+}
+
+// OIDCEnabled reports whether single sign-on is configured.
+func (c Config) OIDCEnabled() bool {
+	return c.OIDCIssuer != ""
 }
 
 func Load() (Config, error) {
@@ -34,7 +134,23 @@ func Load() (Config, error) {
 		WeatherLat:   38.947654,
 		WeatherLon:   -76.476169,
 		WeatherHours: 6,
+		WeatherDays:  5,
 		WidgetTTL:    5 * time.Minute,
+		WidgetSource: SourceLive,
+		DatabasePath: "dev.db",
+		AutoMigrate:  true,
+		SessionTTL:   7 * 24 * time.Hour,
+
+		HistoryRetention: 7 * 24 * time.Hour,
+
+		WeatherUnits:    "imperial",
+		WeatherLanguage: "en",
+		WeatherAlerts:   "nws",
+
+		WeatherProviders: []string{"openmeteo", "nws"},
+
+		HNList:  "top",
+		HNLists: slices.Clone(hnLists),
 	}
 
 	if v := os.Getenv("APP_ENV"); v != "" {
@@ -69,6 +185,68 @@ func Load() (Config, error) {
 		cfg.WeatherHours = n
 	}
 
+	if v := os.Getenv("WEATHER_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 16 {
+			return Config{}, errors.New("invalid WEATHER_DAYS")
+		}
+		cfg.WeatherDays = n
+	}
+
+	if v := os.Getenv("WEATHER_UNITS"); v != "" {
+		cfg.WeatherUnits = v
+	}
+	cfg.WeatherLocation = strings.TrimSpace(os.Getenv("WEATHER_LOCATION"))
+	if v := os.Getenv("WEATHER_LANGUAGE"); v != "" {
+		cfg.WeatherLanguage = v
+	}
+
+	if v := os.Getenv("WEATHER_PROVIDERS"); v != "" {
+		cfg.WeatherProviders = splitList(v)
+	}
+	if v := os.Getenv("WEATHER_ALERTS"); v != "" {
+		cfg.WeatherAlerts = v
+	}
+
+	if v := os.Getenv("HN_LIST"); v != "" {
+		cfg.HNList = v
+	}
+	if v := os.Getenv("HN_LISTS"); v != "" {
+		cfg.HNLists = splitList(v)
+	}
+	cfg.HNInclude = splitList(os.Getenv("HN_INCLUDE"))
+	cfg.HNExclude = splitList(os.Getenv("HN_EXCLUDE"))
+	cfg.HNBlockDomains = splitList(os.Getenv("HN_BLOCK_DOMAINS"))
+	cfg.HNHighlight = splitList(os.Getenv("HN_HIGHLIGHT"))
+	if v := os.Getenv("HN_MIN_SCORE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, errors.New("invalid HN_MIN_SCORE")
+		}
+		cfg.HNMinScore = n
+	}
+	if v := os.Getenv("HN_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, errors.New("invalid HN_MAX_AGE")
+		}
+		cfg.HNMaxAge = d
+	}
+	if v := os.Getenv("HN_ITEM_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, errors.New("invalid HN_ITEM_TTL")
+		}
+		cfg.HNItemTTL = d
+	}
+	if v := os.Getenv("HN_COMMENTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 20 {
+			return Config{}, errors.New("invalid HN_COMMENTS")
+		}
+		cfg.HNComments = n
+	}
+
 	if v := os.Getenv("WIDGET_TTL"); v != "" {
 		d, err := time.ParseDuration(v)
 		if err != nil {
@@ -77,5 +255,164 @@ func Load() (Config, error) {
 		cfg.WidgetTTL = d
 	}
 
+	if v := os.Getenv("HISTORY_RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, errors.New("invalid HISTORY_RETENTION")
+		}
+		cfg.HistoryRetention = d
+	}
+
+	if v := os.Getenv("WIDGET_SOURCE"); v != "" {
+		cfg.WidgetSource = Source(v)
+	}
+
+	cfg.StaticDir = "static"
+	if v := os.Getenv("STATIC_DIR"); v != "" {
+		cfg.StaticDir = v
+	}
+
+	cfg.StaticFromDisk = cfg.Env == EnvDev
+	if v := os.Getenv("STATIC_FROM_DISK"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, errors.New("invalid STATIC_FROM_DISK")
+		}
+		cfg.StaticFromDisk = b
+	}
+
+	if v := os.Getenv("CSP_REPORT_ONLY"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, errors.New("invalid CSP_REPORT_ONLY")
+		}
+		cfg.CSPReportOnly = b
+	}
+
+	if v := os.Getenv("DATABASE_PATH"); v != "" {
+		cfg.DatabasePath = v
+	}
+
+	if v := os.Getenv("AUTO_MIGRATE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, errors.New("invalid AUTO_MIGRATE")
+		}
+		cfg.AutoMigrate = b
+	}
+
+	if v := os.Getenv("SESSION_KEY"); v != "" {
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil || len(key) != 32 {
+			return Config{}, errors.New("invalid SESSION_KEY (want 32 bytes, base64 encoded)")
+		}
+		cfg.SessionKey = key
+	}
+
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, errors.New("invalid SESSION_TTL")
+		}
+		cfg.SessionTTL = d
+	}
+
+	cfg.AuthBootstrapUser = os.Getenv("AUTH_BOOTSTRAP_USER")
+	cfg.AuthBootstrapPassword = os.Getenv("AUTH_BOOTSTRAP_PASSWORD")
+
+	cfg.AuthAllowedGroups = splitList(os.Getenv("AUTH_ALLOWED_GROUPS"))
+
+	if v := os.Getenv("WIDGET_GROUPS"); v != "" {
+		// Format: key=group1|group2,key2=group3
+		cfg.WidgetGroups = make(map[string][]string)
+		for _, rule := range splitList(v) {
+			key, groups, ok := strings.Cut(rule, "=")
+			if !ok || strings.TrimSpace(key) == "" || groups == "" {
+				return Config{}, errors.New("invalid WIDGET_GROUPS")
+			}
+			cfg.WidgetGroups[strings.TrimSpace(key)] = strings.Split(groups, "|")
+		}
+	}
+
+	cfg.OIDCIssuer = os.Getenv("OIDC_ISSUER")
+	cfg.OIDCClientID = os.Getenv("OIDC_CLIENT_ID")
+	cfg.OIDCClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+	cfg.OIDCRedirectURL = os.Getenv("OIDC_REDIRECT_URL")
+	cfg.OIDCScopes = strings.Fields(os.Getenv("OIDC_SCOPES"))
+	cfg.OIDCUsernameClaim = os.Getenv("OIDC_USERNAME_CLAIM")
+	cfg.OIDCGroupsClaim = os.Getenv("OIDC_GROUPS_CLAIM")
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
 	return cfg, nil
 }
+
+// Validate checks that the (parsed) settings make sense together. All problems are
+// reported, joined, rather than just the first.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Env != EnvDev && c.Env != EnvProd {
+		errs = append(errs, errors.New("APP_ENV must be dev or prod"))
+	}
+	if c.WeatherLat < -90 || c.WeatherLat > 90 {
+		errs = append(errs, errors.New("DASHBOARD_LAT must be between -90 and 90"))
+	}
+	if c.WeatherLon < -180 || c.WeatherLon > 180 {
+		errs = append(errs, errors.New("DASHBOARD_LON must be between -180 and 180"))
+	}
+	if c.WeatherUnits != "imperial" && c.WeatherUnits != "metric" {
+		errs = append(errs, errors.New("WEATHER_UNITS must be imperial or metric"))
+	}
+	if len(c.WeatherProviders) == 0 {
+		errs = append(errs, errors.New("WEATHER_PROVIDERS must name at least one provider"))
+	}
+	for _, p := range c.WeatherProviders {
+		if p != "openmeteo" && p != "nws" {
+			errs = append(errs, fmt.Errorf("WEATHER_PROVIDERS: unknown provider %q (want openmeteo or nws)", p))
+		}
+	}
+	if c.WeatherAlerts != "nws" && c.WeatherAlerts != "off" {
+		errs = append(errs, errors.New("WEATHER_ALERTS must be nws or off"))
+	}
+	for _, l := range append([]string{c.HNList}, c.HNLists...) {
+		if !slices.Contains(hnLists, l) {
+			errs = append(errs, fmt.Errorf("HN_LIST/HN_LISTS: unknown list %q (want one of %s)", l, strings.Join(hnLists, ", ")))
+		}
+	}
+	if c.WidgetTTL < 0 {
+		errs = append(errs, errors.New("WIDGET_TTL must not be negative"))
+	}
+	if c.WidgetSource != SourceLive && c.WidgetSource != SourceStore {
+		errs = append(errs, errors.New("WIDGET_SOURCE must be live or store"))
+	}
+	if c.DatabasePath == "" {
+		errs = append(errs, errors.New("DATABASE_PATH must not be empty"))
+	}
+	if len(c.SessionKey) == 0 && c.Env == EnvProd {
+		errs = append(errs, errors.New("SESSION_KEY is required in prod"))
+	}
+	if (c.AuthBootstrapUser == "") != (c.AuthBootstrapPassword == "") {
+		errs = append(errs, errors.New("AUTH_BOOTSTRAP_USER and AUTH_BOOTSTRAP_PASSWORD must be set together"))
+	}
+	if c.OIDCEnabled() && (c.OIDCClientID == "" || c.OIDCRedirectURL == "") {
+		errs = append(errs, errors.New("OIDC_ISSUER requires OIDC_CLIENT_ID and OIDC_REDIRECT_URL"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// hnLists mirrors hn.AllLists (config doesn't import widget packages).
+var hnLists = []string{"top", "new", "best", "ask", "show", "jobs"}
+
+// splitList splits a comma separated env value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -44,6 +44,12 @@ func New(userAgent string) *Client {
 }
 
 func (c *Client) GetJSON(ctx context.Context, url string, out any) error {
+	return c.GetJSONAccept(ctx, url, "application/json", out)
+}
+
+// GetJSONAccept is GetJSON for APIs that want a more specific JSON media type in
+// Accept (e.g. application/geo+json).
+func (c *Client) GetJSONAccept(ctx context.Context, url, accept string, out any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
@@ -51,7 +57,7 @@ func (c *Client) GetJSON(ctx context.Context, url string, out any) error {
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", accept)
 
 	var lastErr error
 	for attempt := 0; attempt <= c.Retries; attempt++ {
@@ -1,3 +1,4 @@
 // Package httpx provides a small HTTP client wrapper for making outbound JSON requests
-// with consistent defaults (timeouts, headers) across widgets.
+// with consistent defaults (timeouts, headers) across widgets, plus shared HTTP helpers
+// such as content-encoding negotiation.
 package httpx
@@ -0,0 +1,51 @@
+package httpx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PreferredEncoding picks the first of supported (in server preference order) that the
+// Accept-Encoding header allows, or "" for identity.
+func PreferredEncoding(acceptEncoding string, supported ...string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	q := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[name] = weight
+	}
+
+	for _, enc := range supported {
+		w, ok := q[enc]
+		if !ok {
+			w, ok = q["*"]
+		}
+		if ok && w > 0 {
+			return enc
+		}
+	}
+	return ""
+}
+
+// Compressible reports whether a Content-Type is worth gzip/brotli compressing.
+func Compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(contentType, ";")
+	ct = strings.TrimSpace(strings.ToLower(ct))
+	switch {
+	case strings.HasPrefix(ct, "text/"):
+		return true
+	case ct == "application/javascript", ct == "application/json", ct == "image/svg+xml":
+		return true
+	}
+	return false
+}
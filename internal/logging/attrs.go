@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// attrBag collects extra attributes for the request log line from handlers and inner
+// middleware.
+type attrBag struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+type bagKey struct{}
+
+// AddAttrs appends attrs to the http_request log line for the request carrying ctx.
+// It is a no-op outside RequestLogger.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+	b, ok := ctx.Value(bagKey{}).(*attrBag)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	b.attrs = append(b.attrs, attrs...)
+	b.mu.Unlock()
+}
+
+func (b *attrBag) list() []slog.Attr {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.attrs
+}
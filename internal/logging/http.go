@@ -1,7 +1,10 @@
 package logging
 
 import (
+	"bufio"
+	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
@@ -29,11 +32,31 @@ func (w *statusRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Flush and Hijack forward to the underlying writer so streaming and upgrades keep
+// working through the logger; Unwrap lets http.ResponseController reach it too.
+func (w *statusRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func RequestLogger(log *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			bag := &attrBag{}
+			r = r.WithContext(context.WithValue(r.Context(), bagKey{}, bag))
+
 			ww := &statusRecorder{ResponseWriter: w}
 			next.ServeHTTP(ww, r)
 
@@ -49,7 +72,7 @@ func RequestLogger(log *slog.Logger) func(http.Handler) http.Handler {
 			reqID := middleware.GetReqID(r.Context())
 			hx := r.Header.Get("HX-Request") == "true"
 
-			log.Info("http_request",
+			attrs := []slog.Attr{
 				slog.String("req_id", reqID),
 				slog.Bool("hx", hx),
 
@@ -62,7 +85,10 @@ func RequestLogger(log *slog.Logger) func(http.Handler) http.Handler {
 				slog.Duration("duration", time.Since(start)),
 
 				slog.String("remote", r.RemoteAddr),
-			)
+			}
+			attrs = append(attrs, bag.list()...)
+
+			log.LogAttrs(r.Context(), slog.LevelInfo, "http_request", attrs...)
 		})
 	}
 }
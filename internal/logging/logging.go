@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
@@ -13,6 +14,11 @@ const (
 )
 
 func New(mode Mode) *slog.Logger {
+	return NewTo(os.Stdout, mode)
+}
+
+// NewTo is New writing to w; CLI commands log to stderr so stdout stays parseable.
+func NewTo(w io.Writer, mode Mode) *slog.Logger {
 	opts := &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 		// AddSource is handy in dev, noisy in prod.
@@ -22,9 +28,9 @@ func New(mode Mode) *slog.Logger {
 	var h slog.Handler
 	switch mode {
 	case ModeDev:
-		h = slog.NewTextHandler(os.Stdout, opts)
+		h = slog.NewTextHandler(w, opts)
 	default:
-		h = slog.NewJSONHandler(os.Stdout, opts)
+		h = slog.NewJSONHandler(w, opts)
 	}
 
 	return slog.New(h)
@@ -0,0 +1,4 @@
+// Package markdown renders user-written Markdown to sanitized HTML that is safe to embed
+// under the app's strict Content-Security-Policy (no inline styles or scripts). Third-party
+// HTML is sanitized with the same policy.
+package markdown
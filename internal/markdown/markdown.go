@@ -0,0 +1,44 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	// GitHub-flavored Markdown. Raw HTML in the source is dropped by goldmark (no
+	// WithUnsafe) and anything else unexpected by the sanitizer.
+	md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	// GFM task list items render as disabled checkboxes.
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render converts src to sanitized HTML.
+func Render(src string) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// Sanitize cleans HTML from elsewhere (e.g. Hacker News comments) with the same policy
+// as rendered Markdown.
+func Sanitize(html string) string {
+	return policy.Sanitize(html)
+}
@@ -0,0 +1,11 @@
+// Package migrate applies versioned SQL migrations embedded in the binary to a SQLite
+// database.
+//
+// Files are named NNNNNN_name.up.sql / NNNNNN_name.down.sql (the golang-migrate "-seq"
+// layout), and the current version lives in the same schema_migrations(version, dirty)
+// table golang-migrate uses, so either tool can be pointed at the database.
+//
+// Each step runs in its own transaction opened with BEGIN IMMEDIATE, which takes SQLite's
+// write lock up front: a second runner blocks (up to the busy timeout), then re-reads the
+// version inside its transaction and skips work that is already done.
+package migrate
@@ -0,0 +1,265 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var fileRe = regexp.MustCompile(`^(\d+)_([\w-]+)\.(up|down)\.sql$`)
+
+// ErrDirty means a previous run (usually golang-migrate) failed mid-migration. Inspect
+// the schema, then clear it with Force.
+var ErrDirty = errors.New("migrate: database is dirty")
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string // empty when there is no down file
+}
+
+// Status describes where a database stands relative to the known migrations.
+type Status struct {
+	Version uint64 // 0 means no migrations applied
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration // ascending by version
+}
+
+// New reads the migrations in fsys (top level only).
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	ms, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: ms}, nil
+}
+
+// Load parses migration files from fsys, sorted by version. Every version needs an up
+// file; down files are optional.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, e := range entries {
+		m := fileRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migrate: bad version in %q", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrate: version %d used by both %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: %06d_%s has no up migration", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Status reports the current version and which migrations are applied or pending.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return Status{}, err
+	}
+	version, dirty, err := readVersion(ctx, m.db)
+	if err != nil {
+		return Status{}, err
+	}
+
+	st := Status{Version: version, Dirty: dirty}
+	for _, mig := range m.migrations {
+		if mig.Version <= version {
+			st.Applied = append(st.Applied, mig)
+		} else {
+			st.Pending = append(st.Pending, mig)
+		}
+	}
+	return st, nil
+}
+
+// Up applies all pending migrations in order and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, mig := range m.migrations {
+		ran, err := m.step(ctx, func(current uint64) (uint64, string, bool) {
+			return mig.Version, mig.Up, mig.Version > current
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migrate: up %06d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if ran {
+			applied = append(applied, mig)
+		}
+	}
+	return applied, nil
+}
+
+// Down reverts up to steps applied migrations, newest first, and returns the ones it
+// reverted. steps <= 0 reverts everything.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if steps > 0 && len(reverted) == steps {
+			break
+		}
+		mig := m.migrations[i]
+		var prev uint64
+		if i > 0 {
+			prev = m.migrations[i-1].Version
+		}
+
+		// A missing down file only matters once this version is the one to revert.
+		noDown := false
+		ran, err := m.step(ctx, func(current uint64) (uint64, string, bool) {
+			if current != mig.Version {
+				return 0, "", false
+			}
+			if mig.Down == "" {
+				noDown = true
+				return 0, "", false
+			}
+			return prev, mig.Down, true
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migrate: down %06d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if noDown {
+			return reverted, fmt.Errorf("migrate: %06d_%s has no down migration", mig.Version, mig.Name)
+		}
+		if ran {
+			reverted = append(reverted, mig)
+		}
+	}
+	return reverted, nil
+}
+
+// Force sets the recorded version and clears the dirty flag without running anything.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	_, err := m.step(ctx, func(uint64) (uint64, string, bool) { return version, "", true })
+	return err
+}
+
+// step runs one migration under the database write lock. plan sees the version as of
+// the lock and returns the version to record, the SQL to run, and whether to proceed.
+func (m *Migrator) step(ctx context.Context, plan func(current uint64) (next uint64, body string, run bool)) (ran bool, err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_, _ = conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		}
+	}()
+
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	next, body, run := plan(current)
+	if !run {
+		_, err = conn.ExecContext(ctx, "COMMIT")
+		return false, err
+	}
+	if dirty && body != "" {
+		return false, fmt.Errorf("%w at version %d", ErrDirty, current)
+	}
+
+	if body != "" {
+		if _, err = conn.ExecContext(ctx, body); err != nil {
+			return false, err
+		}
+	}
+	if _, err = conn.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return false, err
+	}
+	if next > 0 {
+		if _, err = conn.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, dirty) VALUES (?, false)`, next); err != nil {
+			return false, err
+		}
+	}
+	if _, err = conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (version uint64, dirty bool)`)
+	if err != nil {
+		return fmt.Errorf("migrate: create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func readVersion(ctx context.Context, q queryer) (version uint64, dirty bool, err error) {
+	err = q.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("migrate: read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
@@ -0,0 +1,277 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/patrickneise/dashboard/migrations"
+)
+
+// openMemory returns an in-memory database. One connection, since every :memory:
+// connection is a separate database.
+func openMemory(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func newMigrator(t *testing.T, db *sql.DB, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+	var (
+		m   *Migrator
+		err error
+	)
+	if fsys == nil {
+		m, err = New(db, migrations.FS)
+	} else {
+		m, err = New(db, fsys)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	ms, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) == 0 {
+		t.Fatal("no migrations loaded")
+	}
+	for i, m := range ms {
+		if m.Version != uint64(i+1) {
+			t.Errorf("migration %d has version %d, want %d", i, m.Version, i+1)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("%06d_%s: missing up or down SQL", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"down only": {
+			"000001_a.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"name clash": {
+			"000001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"000001_b.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"version zero": {
+			"000000_a.up.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load succeeded, want error", name)
+		}
+	}
+}
+
+func TestUpStatusDown(t *testing.T) {
+	ctx := context.Background()
+	db := openMemory(t)
+	m := newMigrator(t, db, nil)
+	total := len(m.migrations)
+	latest := m.migrations[total-1].Version
+
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Version != 0 || len(st.Applied) != 0 || len(st.Pending) != total {
+		t.Fatalf("fresh status = version %d, %d applied, %d pending", st.Version, len(st.Applied), len(st.Pending))
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != total {
+		t.Fatalf("Up applied %d migrations, want %d", len(applied), total)
+	}
+	for _, table := range []string{"users", "bookmarks", "todos", "notes"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("table %s missing after Up", table)
+		}
+	}
+
+	st, err = m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Version != latest || st.Dirty || len(st.Applied) != total || len(st.Pending) != 0 {
+		t.Fatalf("status after Up = %+v", st)
+	}
+
+	// Re-running Up is a no-op.
+	applied, err = m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("second Up applied %d migrations, want none", len(applied))
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != latest {
+		t.Fatalf("Down(1) reverted %+v, want only version %d", reverted, latest)
+	}
+	if st, _ = m.Status(ctx); st.Version != latest-1 || len(st.Pending) != 1 {
+		t.Fatalf("status after Down(1) = version %d, %d pending", st.Version, len(st.Pending))
+	}
+
+	reverted, err = m.Down(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != total-1 {
+		t.Fatalf("Down(0) reverted %d migrations, want %d", len(reverted), total-1)
+	}
+	if st, _ = m.Status(ctx); st.Version != 0 {
+		t.Fatalf("version after Down(0) = %d, want 0", st.Version)
+	}
+	if tableExists(t, db, "users") {
+		t.Error("users table still exists after reverting everything")
+	}
+
+	// And back up again from scratch.
+	if applied, err = m.Up(ctx); err != nil || len(applied) != total {
+		t.Fatalf("Up after Down = %d applied, err %v", len(applied), err)
+	}
+}
+
+func TestDirtyAndForce(t *testing.T) {
+	ctx := context.Background()
+	db := openMemory(t)
+	fsys := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"000001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"000002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+		"000002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+	}
+	m := newMigrator(t, db, fsys)
+	if err := m.ensureTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A run that failed during version 1 (as golang-migrate records it).
+	if _, err := db.Exec(`CREATE TABLE a (id INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (1, true)`); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Dirty || st.Version != 1 {
+		t.Fatalf("status = version %d dirty %v, want 1 dirty", st.Version, st.Dirty)
+	}
+
+	if _, err := m.Up(ctx); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Up on dirty database: err = %v, want ErrDirty", err)
+	}
+	if tableExists(t, db, "b") {
+		t.Fatal("Up ran a migration on a dirty database")
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Down on dirty database: err = %v, want ErrDirty", err)
+	}
+
+	if err := m.Force(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ = m.Status(ctx); st.Dirty || st.Version != 1 {
+		t.Fatalf("status after Force = version %d dirty %v, want 1 clean", st.Version, st.Dirty)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 || !tableExists(t, db, "b") {
+		t.Fatalf("Up after Force applied %+v, want version 2", applied)
+	}
+
+	// Force to 0 forgets every version without touching the schema.
+	if err := m.Force(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ = m.Status(ctx); st.Version != 0 || len(st.Pending) != 2 {
+		t.Fatalf("status after Force(0) = version %d, %d pending", st.Version, len(st.Pending))
+	}
+	if !tableExists(t, db, "b") {
+		t.Fatal("Force dropped a table")
+	}
+}
+
+func TestDownWithoutDownFile(t *testing.T) {
+	ctx := context.Background()
+	db := openMemory(t)
+
+	v1 := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+		"000001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+	}
+	if _, err := newMigrator(t, db, v1).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A newer, unapplied migration without a down file doesn't block rolling back.
+	v2 := fstest.MapFS{
+		"000001_a.up.sql":   v1["000001_a.up.sql"],
+		"000001_a.down.sql": v1["000001_a.down.sql"],
+		"000002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+	}
+	m := newMigrator(t, db, v2)
+	reverted, err := m.Down(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 1 || tableExists(t, db, "a") {
+		t.Fatalf("Down reverted %+v, want version 1", reverted)
+	}
+
+	// Once applied, it does.
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	reverted, err = m.Down(ctx, 0)
+	if err == nil {
+		t.Fatal("Down succeeded past a migration without a down file")
+	}
+	if len(reverted) != 0 {
+		t.Fatalf("Down reverted %+v before failing, want none", reverted)
+	}
+	if st, _ := m.Status(ctx); st.Version != 2 {
+		t.Fatalf("version after failed Down = %d, want 2", st.Version)
+	}
+}
@@ -0,0 +1,3 @@
+// Package openapi models a minimal OpenAPI 3 document and derives JSON Schemas from Go
+// types via reflection, honoring encoding/json struct tags.
+package openapi
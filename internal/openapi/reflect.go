@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	timeType    = reflect.TypeFor[time.Time]()
+	rawJSONType = reflect.TypeFor[json.RawMessage]()
+
+	// Strips import paths inside generic type arguments: "Envelope[github.com/x/hn.VM]".
+	pkgPathRe = regexp.MustCompile(`[\w.\-]+/`)
+)
+
+// Generator converts Go types to schemas. Named struct types are emitted once into
+// Components.Schemas and referenced with $ref.
+type Generator struct {
+	schemas map[string]*Schema
+}
+
+func NewGenerator() *Generator {
+	return &Generator{schemas: make(map[string]*Schema)}
+}
+
+// Schemas returns the component schemas collected so far.
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// Schema returns the schema for t, registering named structs as components.
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	s := g.schema(t)
+	if nullable {
+		if s.Ref != "" {
+			// OpenAPI 3.0 ignores siblings of $ref, so nullable refs are left as-is.
+			return s
+		}
+		s.Nullable = true
+	}
+	return s
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := ComponentName(t)
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name first so recursive types terminate.
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.Interface:
+		return &Schema{}
+	}
+	return &Schema{}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields follows encoding/json rules: tag names, "-", omitempty (not required), and
+// promotion of embedded struct fields.
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = g.Schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// ComponentName derives a stable schema name like "hn.WidgetViewModel" (or
+// "widgetkit.Envelope_hn.WidgetViewModel" for generic instantiations).
+func ComponentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+
+	name := pkgPathRe.ReplaceAllString(t.Name(), "")
+	name = strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "").Replace(name)
+
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
@@ -0,0 +1,3 @@
+// Package prefs stores per-user widget preferences and carries the current user's
+// preferences through the request context to widget fetchers.
+package prefs
@@ -0,0 +1,51 @@
+package prefs
+
+import (
+	"context"
+	"slices"
+)
+
+type Units string
+
+const (
+	UnitsDefault  Units = ""
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+// Preferences are per-user overrides of the global widget options. Zero values mean
+// "use the configured default".
+type Preferences struct {
+	// Weather home location
+	Lat          *float64
+	Lon          *float64
+	LocationName string
+	Units        Units
+
+	// Hacker News story count
+	HNCount int
+
+	// Widget keys hidden from the dashboard
+	Hidden []string
+}
+
+// HasLocation reports whether both coordinates are set.
+func (p Preferences) HasLocation() bool {
+	return p.Lat != nil && p.Lon != nil
+}
+
+func (p Preferences) IsHidden(key string) bool {
+	return slices.Contains(p.Hidden, key)
+}
+
+type ctxKey struct{}
+
+func WithPreferences(ctx context.Context, p Preferences) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the preferences attached to ctx, or the zero value (all defaults).
+func FromContext(ctx context.Context) Preferences {
+	p, _ := ctx.Value(ctxKey{}).(Preferences)
+	return p
+}
@@ -0,0 +1,100 @@
+package prefs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+// Service loads and saves preferences in the user_preferences table.
+type Service struct {
+	q   *store.Queries
+	log *slog.Logger
+}
+
+func NewService(q *store.Queries, log *slog.Logger) *Service {
+	return &Service{q: q, log: log}
+}
+
+func (s *Service) Load(ctx context.Context, userID int64) (Preferences, error) {
+	row, err := s.q.GetUserPreferences(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Preferences{}, nil
+		}
+		return Preferences{}, err
+	}
+
+	p := Preferences{
+		Lat:    row.Lat,
+		Lon:    row.Lon,
+		Hidden: splitHidden(row.HiddenWidgets),
+	}
+	if row.LocationName != nil {
+		p.LocationName = *row.LocationName
+	}
+	if row.Units != nil {
+		p.Units = Units(*row.Units)
+	}
+	if row.HnCount != nil {
+		p.HNCount = int(*row.HnCount)
+	}
+	return p, nil
+}
+
+func (s *Service) Save(ctx context.Context, userID int64, p Preferences) error {
+	arg := store.UpsertUserPreferencesParams{
+		UserID:        userID,
+		Lat:           p.Lat,
+		Lon:           p.Lon,
+		HiddenWidgets: strings.Join(p.Hidden, ","),
+	}
+	if p.LocationName != "" {
+		arg.LocationName = &p.LocationName
+	}
+	if p.Units != UnitsDefault {
+		u := string(p.Units)
+		arg.Units = &u
+	}
+	if p.HNCount > 0 {
+		n := int64(p.HNCount)
+		arg.HnCount = &n
+	}
+	return s.q.UpsertUserPreferences(ctx, arg)
+}
+
+// Middleware attaches the signed-in user's preferences to the request context. It must
+// run after auth.LoadSession. Load failures fall back to defaults rather than failing
+// the request.
+func (s *Service) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p, err := s.Load(r.Context(), u.ID)
+		if err != nil && s.log != nil {
+			s.log.Warn("prefs_load_failed", slog.Int64("user_id", u.ID), slog.Any("err", err))
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPreferences(r.Context(), p)))
+	})
+}
+
+func splitHidden(v string) []string {
+	var out []string
+	for _, k := range strings.Split(v, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
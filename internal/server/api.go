@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/prefs"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+type widgetIndexEntry struct {
+	Key     string `json:"key"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	APIURL  string `json:"api_url,omitempty"`
+	Hidden  bool   `json:"hidden"`
+}
+
+// registerAPIRoutes mounts the JSON API: an index of widgets generated from the registry,
+// /api/widgets/<key> for every widget whose handler implements widgetkit.DataHandler, and
+// the OpenAPI description of both.
+// Must be inside the RequireUser group.
+func registerAPIRoutes(r chi.Router, specs []widgetkit.Spec) {
+	r.Get("/api/openapi.json", openAPIHandler(buildOpenAPI(specs)))
+
+	r.Route("/api/widgets", func(ar chi.Router) {
+		ar.Get("/", func(w http.ResponseWriter, req *http.Request) {
+			u, _ := auth.UserFromContext(req.Context())
+			p := prefs.FromContext(req.Context())
+
+			out := make([]widgetIndexEntry, 0, len(specs))
+			for _, s := range specs {
+				if !u.InAnyGroup(s.Groups) {
+					continue
+				}
+				e := widgetIndexEntry{
+					Key:     s.Key,
+					Title:   s.Title,
+					HTMLURL: "/widgets/" + s.Key,
+					Hidden:  p.IsHidden(s.Key),
+				}
+				if _, ok := s.Handler.(widgetkit.DataHandler); ok {
+					e.APIURL = "/api/widgets/" + s.Key
+				}
+				out = append(out, e)
+			}
+
+			writeJSON(w, http.StatusOK, out)
+		})
+
+		for _, s := range specs {
+			dh, ok := s.Handler.(widgetkit.DataHandler)
+			if !ok {
+				continue
+			}
+			ar.Handle("/"+s.Key, requireGroups(s.Groups, http.HandlerFunc(dh.ServeJSON)))
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/ui/pages"
+)
+
+// registerAuthRoutes mounts the login/logout endpoints (and the OIDC flow when sso is
+// non-nil). They must stay outside the RequireUser group.
+func registerAuthRoutes(r chi.Router, a *auth.Auth, sso *auth.OIDC) {
+	r.Get("/login", func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := auth.UserFromContext(req.Context()); ok {
+			http.Redirect(w, req, auth.SafeNext(req.URL.Query().Get("next")), http.StatusSeeOther)
+			return
+		}
+		renderLogin(w, req, http.StatusOK, pages.LoginProps{
+			Next: req.URL.Query().Get("next"),
+			SSO:  sso != nil,
+		})
+	})
+
+	r.Post("/login", func(w http.ResponseWriter, req *http.Request) {
+		username := req.PostFormValue("username")
+		next := req.PostFormValue("next")
+
+		u, err := a.Authenticate(req.Context(), username, req.PostFormValue("password"))
+		if err != nil {
+			status := http.StatusUnauthorized
+			msg := "Invalid username or password."
+			if !errors.Is(err, auth.ErrInvalidCredentials) {
+				slog.ErrorContext(req.Context(), "login_failed", slog.Any("err", err))
+				status = http.StatusInternalServerError
+				msg = "Something went wrong, please try again."
+			}
+			renderLogin(w, req, status, pages.LoginProps{Username: username, Next: next, Error: msg, SSO: sso != nil})
+			return
+		}
+
+		if err := a.StartSession(w, u); err != nil {
+			slog.ErrorContext(req.Context(), "session_start_failed", slog.Any("err", err))
+			http.Error(w, "session error", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, req, auth.SafeNext(next), http.StatusSeeOther)
+	})
+
+	r.Post("/logout", func(w http.ResponseWriter, req *http.Request) {
+		a.EndSession(w)
+		http.Redirect(w, req, "/login", http.StatusSeeOther)
+	})
+
+	if sso == nil {
+		return
+	}
+
+	r.Get("/auth/oidc/login", func(w http.ResponseWriter, req *http.Request) {
+		next := req.URL.Query().Get("next")
+		if err := sso.Begin(w, req, next); err != nil {
+			slog.ErrorContext(req.Context(), "oidc_begin_failed", slog.Any("err", err))
+			renderLogin(w, req, http.StatusBadGateway, pages.LoginProps{
+				Next:  next,
+				SSO:   true,
+				Error: "Single sign-on is unavailable right now.",
+			})
+		}
+	})
+
+	r.Get("/auth/oidc/callback", func(w http.ResponseWriter, req *http.Request) {
+		u, next, err := sso.Complete(w, req)
+		if err != nil {
+			slog.WarnContext(req.Context(), "oidc_callback_failed", slog.Any("err", err))
+			msg := "Single sign-on failed, please try again."
+			if errors.Is(err, auth.ErrUsernameTaken) {
+				msg = "Your account name is already used by a local account."
+			}
+			renderLogin(w, req, http.StatusUnauthorized, pages.LoginProps{SSO: true, Error: msg})
+			return
+		}
+
+		if err := a.StartSession(w, u); err != nil {
+			slog.ErrorContext(req.Context(), "session_start_failed", slog.Any("err", err))
+			http.Error(w, "session error", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, req, next, http.StatusSeeOther)
+	})
+}
+
+func renderLogin(w http.ResponseWriter, req *http.Request, status int, p pages.LoginProps) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = pages.LoginPage(p).Render(req.Context(), w)
+}
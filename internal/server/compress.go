@@ -0,0 +1,215 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/patrickneise/dashboard/internal/httpx"
+	"github.com/patrickneise/dashboard/internal/logging"
+)
+
+var (
+	gzipPool = sync.Pool{New: func() any {
+		zw, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return zw
+	}}
+	brotliPool = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}}
+)
+
+// Compress gzip/brotli-encodes text responses (HTML fragments, JSON, ...) once they
+// reach minSize bytes. Responses that already carry a Content-Encoding (e.g. precompressed
+// static assets) pass through untouched. It must sit inside logging.RequestLogger: the
+// logger's "bytes" is then what went over the wire and Compress adds "bytes_raw" and
+// "encoding" to the same log line. Flush is preserved for streaming handlers.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enc := httpx.PreferredEncoding(r.Header.Get("Accept-Encoding"), "br", "gzip")
+			if enc == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, enc: enc, minSize: minSize}
+			defer func() {
+				_ = cw.Close()
+				if cw.zw != nil {
+					logging.AddAttrs(r.Context(),
+						slog.String("encoding", cw.enc),
+						slog.Int("bytes_raw", cw.raw))
+				}
+			}()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+
+	enc     string
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	zw      io.WriteCloser // nil when passing through uncompressed
+	raw     int
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if code >= 100 && code < 200 {
+		// Informational responses (103 Early Hints) go straight through.
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.raw += len(b)
+
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide picks compressed vs identity, sends the headers, and drains the buffer. With
+// force (a Flush before minSize bytes) compression is chosen regardless of size so
+// streamed responses stay encoded end to end.
+func (w *compressWriter) decide(force bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	eligible := h.Get("Content-Encoding") == "" &&
+		bodyAllowed(w.status) &&
+		httpx.Compressible(h.Get("Content-Type"))
+	if eligible {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	if eligible && (force || len(w.buf) >= w.minSize) {
+		h.Set("Content-Encoding", w.enc)
+		h.Del("Content-Length")
+		// The encoded body is a different representation; a weak validator still lets
+		// If-None-Match revalidation work.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.zw = w.newEncoder()
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.zw != nil {
+		_, err := w.zw.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.enc == "br" {
+		bw := brotliPool.Get().(*brotli.Writer)
+		bw.Reset(w.ResponseWriter)
+		return bw
+	}
+	zw := gzipPool.Get().(*gzip.Writer)
+	zw.Reset(w.ResponseWriter)
+	return zw
+}
+
+// Close finishes the response: small bodies are written uncompressed, encoders are
+// flushed and returned to their pool.
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			// Handler wrote nothing; let net/http send its default response.
+			w.decided = true
+			return nil
+		}
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.zw == nil {
+		return nil
+	}
+
+	err := w.zw.Close()
+	switch zw := w.zw.(type) {
+	case *gzip.Writer:
+		gzipPool.Put(zw)
+	case *brotli.Writer:
+		brotliPool.Put(zw)
+	}
+	return err
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if f, ok := w.zw.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.decided {
+		return nil, nil, errors.New("compress: cannot hijack after the response has started")
+	}
+	w.decided = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified && status >= 200
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/andybalholm/brotli"
+
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+const testMinSize = 1024
+
+var largeBody = strings.Repeat("<li>a compressible line of html</li>\n", 100)
+
+// serve runs h behind Compress and returns the recorded response.
+func serve(h http.Handler, acceptEncoding string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	Compress(testMinSize)(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func html(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func decode(t *testing.T, enc string, body io.Reader) string {
+	t.Helper()
+	var r io.Reader
+	switch enc {
+	case "gzip":
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		r = zr
+	case "br":
+		r = brotli.NewReader(body)
+	default:
+		r = body
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decode %s: %v", enc, err)
+	}
+	return string(b)
+}
+
+func TestCompressSmallBodyPassesThrough(t *testing.T) {
+	rec := serve(html("<p>hi</p>"), "gzip, br", nil)
+
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q, want none", enc)
+	}
+	if got := rec.Body.String(); got != "<p>hi</p>" {
+		t.Errorf("body = %q", got)
+	}
+	// The answer would differ for a larger body, so caches still need to key on it.
+	if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", vary)
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"v1"` {
+		t.Errorf("ETag = %q, want it unchanged", etag)
+	}
+}
+
+func TestCompressLargeBody(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0, gzip", "gzip"},
+		{"identity", ""},
+		{"*;q=0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			rec := serve(html(largeBody), tt.accept, nil)
+
+			if enc := rec.Header().Get("Content-Encoding"); enc != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", enc, tt.want)
+			}
+			if got := decode(t, tt.want, rec.Body); got != largeBody {
+				t.Errorf("decoded body differs (%d bytes, want %d)", len(got), len(largeBody))
+			}
+			if tt.want == "" {
+				return
+			}
+			if rec.Body.Len() >= len(largeBody) {
+				t.Errorf("encoded %d bytes, not smaller than %d", rec.Body.Len(), len(largeBody))
+			}
+			if vary := rec.Header().Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", vary)
+			}
+			if etag := rec.Header().Get("ETag"); etag != `W/"v1"` {
+				t.Errorf("ETag = %q, want W/\"v1\"", etag)
+			}
+		})
+	}
+}
+
+func TestCompressDropsContentLength(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "9999")
+		_, _ = io.WriteString(w, largeBody)
+	})
+	rec := serve(h, "gzip", nil)
+
+	if cl := rec.Header().Get("Content-Length"); cl != "" {
+		t.Errorf("Content-Length = %q, want it dropped", cl)
+	}
+}
+
+func TestCompressSkipsIneligible(t *testing.T) {
+	tests := []struct {
+		name string
+		h    http.HandlerFunc
+	}{
+		{"already encoded", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/css")
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = io.WriteString(w, largeBody)
+		}},
+		{"not compressible", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, largeBody)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.h, "br, gzip", nil)
+
+			if got := rec.Body.String(); got != largeBody {
+				t.Errorf("body was rewritten (%d bytes)", len(got))
+			}
+			if vary := rec.Header().Get("Vary"); vary != "" {
+				t.Errorf("Vary = %q, want none", vary)
+			}
+		})
+	}
+	if enc := serve(tests[0].h, "br", nil).Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Errorf("Content-Encoding = %q, want the handler's gzip", enc)
+	}
+}
+
+// A widget fragment served compressed carries a weakened ETag; sending it back must
+// still revalidate to a bodiless 304.
+func TestCompressNotModifiedWithWeakETag(t *testing.T) {
+	widget := widgetkit.Handler[string]{
+		Name:   "test",
+		Fetch:  func(context.Context) (string, error) { return largeBody, nil },
+		Render: func(s string) templ.Component { return templ.Raw(s) },
+	}
+
+	first := serve(widget, "gzip", nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("ETag = %q, want a weak validator", etag)
+	}
+
+	second := serve(widget, "gzip", http.Header{"If-None-Match": {etag}})
+	if second.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", second.Code)
+	}
+	if second.Body.Len() != 0 {
+		t.Errorf("304 has a %d byte body", second.Body.Len())
+	}
+	if enc := second.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("304 Content-Encoding = %q, want none", enc)
+	}
+}
+
+func TestCompressFlushStartsEncoding(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: one\n\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+		_, _ = io.WriteString(w, "data: two\n\n")
+	})
+	rec := serve(h, "gzip", nil)
+
+	if !rec.Flushed {
+		t.Error("flush did not reach the underlying writer")
+	}
+	if enc := rec.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip below minSize once flushed", enc)
+	}
+	if got := decode(t, "gzip", rec.Body); got != "data: one\n\ndata: two\n\n" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestCompressHijack(t *testing.T) {
+	srv := httptest.NewServer(Compress(testMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/late" {
+			_, _ = io.WriteString(w, largeBody)
+			if _, _, err := http.NewResponseController(w).Hijack(); err == nil {
+				t.Error("hijack after writing the response succeeded")
+			}
+			return
+		}
+
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = buf.Flush()
+	})))
+	defer srv.Close()
+
+	for _, path := range []string{"/", "/late"} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: read: %v", path, err)
+		}
+
+		if path == "/" {
+			if string(body) != "hijacked" {
+				t.Errorf("hijacked body = %q", body)
+			}
+			continue
+		}
+		if got := decode(t, resp.Header.Get("Content-Encoding"), bytes.NewReader(body)); got != largeBody {
+			t.Errorf("%s: body differs after failed hijack", path)
+		}
+	}
+}
+
+func TestCompressHijackUnsupported(t *testing.T) {
+	var err error
+	serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err = http.NewResponseController(w).Hijack()
+	}), "gzip", nil)
+
+	// The recorder can't hijack; the wrapper must hand the call through rather than
+	// claim support itself.
+	if !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("hijack err = %v, want http.ErrNotSupported", err)
+	}
+}
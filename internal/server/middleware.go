@@ -3,7 +3,7 @@ package server
 import "net/http"
 
 // SecurityHeaders adds a baseline set of safe headers.
-// Keep CSP out for now until you fully control scripts/styles (no CDN).
+// CSP is handled separately by ContentSecurityPolicy since it needs a per-request nonce.
 func SecurityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Basic hardening
@@ -17,3 +17,16 @@ func SecurityHeaders(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// CSRF rejects cross-origin state-changing requests (POST, PUT, DELETE, ...) using the
+// browser's Sec-Fetch-Site / Origin headers. Safe methods pass through untouched, so
+// HTMX GET polling is unaffected.
+func CSRF(next http.Handler) http.Handler {
+	cop := http.NewCrossOriginProtection()
+	// Browsers POST violation reports without Origin guarantees; the endpoint only logs.
+	cop.AddInsecureBypassPattern("POST " + cspReportPath)
+	cop.SetDenyHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+	}))
+	return cop.Handler(next)
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/openapi"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+// buildOpenAPI describes the JSON API from the registered widgets. It runs once at
+// startup, so adding a widget to the registry is enough to document its endpoint.
+func buildOpenAPI(specs []widgetkit.Spec) *openapi.Document {
+	gen := openapi.NewGenerator()
+	errSchema := gen.Schema(reflect.TypeFor[widgetkit.ErrorEnvelope]())
+
+	doc := &openapi.Document{
+		OpenAPI: "3.0.3",
+		Info: openapi.Info{
+			Title:       "Dashboard API",
+			Version:     "0.1",
+			Description: "JSON view of the dashboard widgets. Authenticated with the session cookie.",
+		},
+		Paths: map[string]openapi.PathItem{
+			"/api/widgets": {Get: &openapi.Operation{
+				OperationID: "listWidgets",
+				Summary:     "List widgets visible to the current user",
+				Tags:        []string{"widgets"},
+				Responses: map[string]openapi.Response{
+					"200": openapi.JSONResponse("Registered widgets", gen.Schema(reflect.TypeFor[[]widgetIndexEntry]())),
+					"401": {Description: "Not signed in"},
+				},
+			}},
+		},
+		Security: []map[string][]string{{"session": {}}},
+	}
+
+	for _, s := range specs {
+		dh, ok := s.Handler.(widgetkit.DataHandler)
+		if !ok {
+			continue
+		}
+		doc.Paths["/api/widgets/"+s.Key] = openapi.PathItem{Get: &openapi.Operation{
+			OperationID: "getWidget_" + s.Key,
+			Summary:     s.Title + " widget data",
+			Tags:        []string{"widgets"},
+			Responses: map[string]openapi.Response{
+				"200": openapi.JSONResponse(s.Title+" view model with cache metadata", gen.Schema(dh.JSONType())),
+				"304": {Description: "Not modified (If-None-Match matched the ETag)"},
+				"401": {Description: "Not signed in"},
+				"403": {Description: "Widget restricted to other groups"},
+				"502": openapi.JSONResponse("Upstream fetch failed and no cached data is available", errSchema),
+			},
+		}}
+	}
+
+	doc.Components = openapi.Components{
+		Schemas: gen.Schemas(),
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			"session": {Type: "apiKey", In: "cookie", Name: auth.SessionCookieName},
+		},
+	}
+	return doc
+}
+
+func openAPIHandler(doc *openapi.Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, doc)
+	}
+}
@@ -1,25 +1,57 @@
 package server
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/patrickneise/dashboard/internal/assets"
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/prefs"
 	"github.com/patrickneise/dashboard/internal/ui/components"
 	"github.com/patrickneise/dashboard/internal/ui/pages"
 	"github.com/patrickneise/dashboard/internal/widgetkit"
 )
 
-func RegisterRoutes(r chi.Router, reg *widgetkit.Registry) {
-	if reg == nil {
+// Deps are the services the routes need.
+type Deps struct {
+	Registry *widgetkit.Registry
+	Auth     *auth.Auth
+	SSO      *auth.OIDC // nil when single sign-on is not configured
+	Prefs    *prefs.Service
+	Assets   *assets.Assets
+	Log      *slog.Logger
+}
+
+func RegisterRoutes(r chi.Router, d Deps) {
+	if d.Registry == nil {
 		panic("server.RegisterRoutes: registry is nil")
 	}
+	if d.Auth == nil {
+		panic("server.RegisterRoutes: auth is nil")
+	}
+	if d.Prefs == nil {
+		panic("server.RegisterRoutes: prefs is nil")
+	}
+	if d.Assets == nil {
+		panic("server.RegisterRoutes: assets is nil")
+	}
+	if d.Log == nil {
+		d.Log = slog.Default()
+	}
 
 	// Static assets
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	r.Handle(assets.URLPrefix+"*", http.StripPrefix(assets.URLPrefix, d.Assets.Handler()))
+
+	// CSP violation reports (public; browsers may send them without cookies)
+	r.Post(cspReportPath, CSPReportHandler(d.Log))
+
+	// Login/logout (public)
+	registerAuthRoutes(r, d.Auth, d.SSO)
 
 	// Build dashboard cards once (registry is startup-time config)
-	specs := reg.List()
+	specs := d.Registry.List()
 	cards := make([]components.WidgetCardProps, 0, len(specs))
 	for _, s := range specs {
 		cards = append(cards, components.WidgetCardProps{
@@ -30,18 +62,61 @@ func RegisterRoutes(r chi.Router, reg *widgetkit.Registry) {
 		})
 	}
 
-	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
-		component := pages.DashboardPage(cards)
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		if err := component.Render(req.Context(), w); err != nil {
-			http.Error(w, "render error", http.StatusInternalServerError)
-		}
+	// Everything below requires a signed-in user
+	r.Group(func(pr chi.Router) {
+		pr.Use(d.Auth.RequireUser)
+
+		pr.Get("/", func(w http.ResponseWriter, req *http.Request) {
+			u, _ := auth.UserFromContext(req.Context())
+			p := prefs.FromContext(req.Context())
+
+			visible := make([]components.WidgetCardProps, 0, len(cards))
+			for i, s := range specs {
+				if u.InAnyGroup(s.Groups) && !p.IsHidden(s.Key) {
+					visible = append(visible, cards[i])
+				}
+			}
+
+			component := pages.DashboardPage(u.Username, visible)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := component.Render(req.Context(), w); err != nil {
+				http.Error(w, "render error", http.StatusInternalServerError)
+			}
+		})
+
+		registerSettingsRoutes(pr, specs, d.Prefs)
+		registerAPIRoutes(pr, specs)
+
+		// Widgets auto-mounted under /widgets/<key>; widgets with write routes get a
+		// subrouter (see widgetkit.Mounter).
+		pr.Route("/widgets", func(wr chi.Router) {
+			for _, s := range specs {
+				m, ok := s.Handler.(widgetkit.Mounter)
+				if !ok {
+					wr.Handle("/"+s.Key, requireGroups(s.Groups, s.Handler))
+					continue
+				}
+				wr.Route("/"+s.Key, func(kr chi.Router) {
+					kr.Use(func(next http.Handler) http.Handler { return requireGroups(s.Groups, next) })
+					kr.Get("/", s.Handler.ServeHTTP)
+					m.Mount(kr)
+				})
+			}
+		})
 	})
+}
 
-	// Widgets auto-mounted under /widgets/<key>
-	r.Route("/widgets", func(wr chi.Router) {
-		for _, s := range specs {
-			wr.Handle("/"+s.Key, s.Handler)
+// requireGroups returns 403 unless the signed-in user is in one of groups.
+func requireGroups(groups []string, next http.Handler) http.Handler {
+	if len(groups) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u, _ := auth.UserFromContext(req.Context())
+		if !u.InAnyGroup(groups) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
 		}
+		next.ServeHTTP(w, req)
 	})
 }
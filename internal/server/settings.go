@@ -0,0 +1,141 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/prefs"
+	"github.com/patrickneise/dashboard/internal/ui/pages"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+// registerSettingsRoutes mounts the per-user preferences page. Must be inside the
+// RequireUser group.
+func registerSettingsRoutes(r chi.Router, specs []widgetkit.Spec, ps *prefs.Service) {
+	r.Get("/settings", func(w http.ResponseWriter, req *http.Request) {
+		p := prefs.FromContext(req.Context())
+		props := settingsProps(p, specs)
+		props.Saved = req.URL.Query().Get("saved") == "1"
+		renderSettings(w, req, http.StatusOK, props)
+	})
+
+	r.Post("/settings", func(w http.ResponseWriter, req *http.Request) {
+		u, _ := auth.UserFromContext(req.Context())
+
+		p, err := parseSettingsForm(req, specs)
+		if err != nil {
+			props := settingsFormProps(req, specs)
+			props.Error = err.Error()
+			renderSettings(w, req, http.StatusUnprocessableEntity, props)
+			return
+		}
+
+		if err := ps.Save(req.Context(), u.ID, p); err != nil {
+			slog.ErrorContext(req.Context(), "prefs_save_failed", slog.Int64("user_id", u.ID), slog.Any("err", err))
+			props := settingsFormProps(req, specs)
+			props.Error = "Couldn't save settings, please try again."
+			renderSettings(w, req, http.StatusInternalServerError, props)
+			return
+		}
+
+		http.Redirect(w, req, "/settings?saved=1", http.StatusSeeOther)
+	})
+}
+
+func parseSettingsForm(req *http.Request, specs []widgetkit.Spec) (prefs.Preferences, error) {
+	var p prefs.Preferences
+
+	latStr := strings.TrimSpace(req.PostFormValue("lat"))
+	lonStr := strings.TrimSpace(req.PostFormValue("lon"))
+	if (latStr == "") != (lonStr == "") {
+		return p, formError("Set both latitude and longitude, or neither.")
+	}
+	if latStr != "" {
+		lat, err := strconv.ParseFloat(latStr, 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return p, formError("Latitude must be a number between -90 and 90.")
+		}
+		lon, err := strconv.ParseFloat(lonStr, 64)
+		if err != nil || lon < -180 || lon > 180 {
+			return p, formError("Longitude must be a number between -180 and 180.")
+		}
+		p.Lat, p.Lon = &lat, &lon
+	}
+
+	p.LocationName = strings.TrimSpace(req.PostFormValue("location_name"))
+
+	switch u := prefs.Units(req.PostFormValue("units")); u {
+	case prefs.UnitsDefault, prefs.UnitsMetric, prefs.UnitsImperial:
+		p.Units = u
+	default:
+		return p, formError("Unknown units.")
+	}
+
+	if v := strings.TrimSpace(req.PostFormValue("hn_count")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 50 {
+			return p, formError("Number of stories must be between 1 and 50.")
+		}
+		p.HNCount = n
+	}
+
+	shown := req.PostForm["show"]
+	for _, s := range specs {
+		if !slices.Contains(shown, s.Key) {
+			p.Hidden = append(p.Hidden, s.Key)
+		}
+	}
+
+	return p, nil
+}
+
+func settingsProps(p prefs.Preferences, specs []widgetkit.Spec) pages.SettingsProps {
+	props := pages.SettingsProps{
+		LocationName: p.LocationName,
+		Units:        string(p.Units),
+	}
+	if p.HasLocation() {
+		props.Lat = strconv.FormatFloat(*p.Lat, 'f', -1, 64)
+		props.Lon = strconv.FormatFloat(*p.Lon, 'f', -1, 64)
+	}
+	if p.HNCount > 0 {
+		props.HNCount = strconv.Itoa(p.HNCount)
+	}
+	for _, s := range specs {
+		props.Widgets = append(props.Widgets, pages.WidgetToggle{Key: s.Key, Title: s.Title, Visible: !p.IsHidden(s.Key)})
+	}
+	return props
+}
+
+// settingsFormProps echoes the submitted form back so the user can fix errors.
+func settingsFormProps(req *http.Request, specs []widgetkit.Spec) pages.SettingsProps {
+	props := pages.SettingsProps{
+		Lat:          req.PostFormValue("lat"),
+		Lon:          req.PostFormValue("lon"),
+		LocationName: req.PostFormValue("location_name"),
+		Units:        req.PostFormValue("units"),
+		HNCount:      req.PostFormValue("hn_count"),
+	}
+	shown := req.PostForm["show"]
+	for _, s := range specs {
+		props.Widgets = append(props.Widgets, pages.WidgetToggle{Key: s.Key, Title: s.Title, Visible: slices.Contains(shown, s.Key)})
+	}
+	return props
+}
+
+// formError is a validation message shown to the user as-is.
+type formError string
+
+func (e formError) Error() string { return string(e) }
+
+func renderSettings(w http.ResponseWriter, req *http.Request, status int, p pages.SettingsProps) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = pages.SettingsPage(p).Render(req.Context(), w)
+}
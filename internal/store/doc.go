@@ -0,0 +1,3 @@
+// Package store provides SQLite persistence for the dashboard. Query code is generated by
+// sqlc from queries.sql against the schema in migrations/.
+package store
@@ -0,0 +1,163 @@
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = ? LIMIT 1;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users
+WHERE username = ? LIMIT 1;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: CreateUser :one
+INSERT INTO users (username, password_hash)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: TouchUserLogin :exec
+UPDATE users SET last_login_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetUserByIdentity :one
+SELECT users.* FROM users
+JOIN user_identities ON user_identities.user_id = users.id
+WHERE user_identities.issuer = ? AND user_identities.subject = ?
+LIMIT 1;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (issuer, subject, user_id)
+VALUES (?, ?, ?);
+
+-- name: ListUserGroups :many
+SELECT group_name FROM user_groups
+WHERE user_id = ?
+ORDER BY group_name;
+
+-- name: DeleteUserGroups :exec
+DELETE FROM user_groups
+WHERE user_id = ?;
+
+-- name: AddUserGroup :exec
+INSERT OR IGNORE INTO user_groups (user_id, group_name)
+VALUES (?, ?);
+
+-- name: GetUserPreferences :one
+SELECT * FROM user_preferences
+WHERE user_id = ? LIMIT 1;
+
+-- name: UpsertUserPreferences :exec
+INSERT INTO user_preferences (user_id, lat, lon, location_name, units, hn_count, hidden_widgets, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (user_id) DO UPDATE SET
+    lat            = excluded.lat,
+    lon            = excluded.lon,
+    location_name  = excluded.location_name,
+    units          = excluded.units,
+    hn_count       = excluded.hn_count,
+    hidden_widgets = excluded.hidden_widgets,
+    updated_at     = CURRENT_TIMESTAMP;
+
+-- name: GetWidgetSnapshot :one
+SELECT * FROM widget_snapshots
+WHERE widget = ? AND variant = ? LIMIT 1;
+
+-- name: UpsertWidgetSnapshot :exec
+INSERT INTO widget_snapshots (widget, variant, data, fetched_at, expires_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (widget, variant) DO UPDATE SET
+    data       = excluded.data,
+    fetched_at = excluded.fetched_at,
+    expires_at = excluded.expires_at;
+
+-- name: AppendWidgetHistory :exec
+INSERT INTO widget_history (widget, variant, data, fetched_at)
+VALUES (?, ?, ?, ?);
+
+-- name: ListWidgetHistory :many
+SELECT data, fetched_at FROM widget_history
+WHERE widget = ? AND variant = ? AND fetched_at >= ?
+ORDER BY fetched_at;
+
+-- name: PruneWidgetHistory :execrows
+DELETE FROM widget_history
+WHERE widget = ? AND fetched_at < ?;
+
+-- name: ListBookmarks :many
+SELECT * FROM bookmarks
+WHERE user_id = ?
+ORDER BY group_name COLLATE NOCASE, name COLLATE NOCASE, id;
+
+-- name: GetBookmark :one
+SELECT * FROM bookmarks
+WHERE id = ? AND user_id = ? LIMIT 1;
+
+-- name: CreateBookmark :exec
+INSERT INTO bookmarks (user_id, group_name, name, url, icon, tags)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: UpdateBookmark :execrows
+UPDATE bookmarks
+SET group_name = ?, name = ?, url = ?, icon = ?, tags = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND user_id = ?;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE id = ? AND user_id = ?;
+
+-- name: ListTodos :many
+SELECT * FROM todos
+ORDER BY position, id;
+
+-- name: GetTodo :one
+SELECT * FROM todos
+WHERE id = ? LIMIT 1;
+
+-- name: CreateTodo :exec
+INSERT INTO todos (text, position)
+VALUES (?, (SELECT COALESCE(MAX(position), 0) + 1 FROM todos));
+
+-- name: ToggleTodo :execrows
+UPDATE todos
+SET done    = NOT done,
+    done_at = CASE WHEN done THEN NULL ELSE CURRENT_TIMESTAMP END
+WHERE id = ?;
+
+-- name: DeleteTodo :execrows
+DELETE FROM todos
+WHERE id = ?;
+
+-- name: GetTodoBefore :one
+SELECT * FROM todos
+WHERE position < ?
+ORDER BY position DESC LIMIT 1;
+
+-- name: GetTodoAfter :one
+SELECT * FROM todos
+WHERE position > ?
+ORDER BY position LIMIT 1;
+
+-- name: SetTodoPosition :exec
+UPDATE todos
+SET position = ?
+WHERE id = ?;
+
+-- name: ListNotes :many
+SELECT * FROM notes
+ORDER BY updated_at DESC, id DESC;
+
+-- name: GetNote :one
+SELECT * FROM notes
+WHERE id = ? LIMIT 1;
+
+-- name: CreateNote :exec
+INSERT INTO notes (body)
+VALUES (?);
+
+-- name: UpdateNote :execrows
+UPDATE notes
+SET body = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteNote :execrows
+DELETE FROM notes
+WHERE id = ?;
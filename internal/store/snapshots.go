@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LoadSnapshot returns the stored snapshot for a widget variant; found is false when the
+// worker has not written one yet. Together with SaveSnapshot it satisfies
+// widgetkit.SnapshotStore.
+func (s *Store) LoadSnapshot(ctx context.Context, widget, variant string) (data []byte, expiresAt time.Time, found bool, err error) {
+	snap, err := s.GetWidgetSnapshot(ctx, GetWidgetSnapshotParams{Widget: widget, Variant: variant})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return snap.Data, snap.ExpiresAt, true, nil
+}
+
+// SaveSnapshot replaces the snapshot for a widget variant.
+func (s *Store) SaveSnapshot(ctx context.Context, widget, variant string, data []byte, fetchedAt, expiresAt time.Time) error {
+	return s.UpsertWidgetSnapshot(ctx, UpsertWidgetSnapshotParams{
+		Widget:    widget,
+		Variant:   variant,
+		Data:      data,
+		FetchedAt: fetchedAt.UTC(),
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
+
+// AppendHistory records one fetched value. With ListHistory and PruneHistory it
+// satisfies widgetkit.HistoryStore.
+func (s *Store) AppendHistory(ctx context.Context, widget, variant string, data []byte, fetchedAt time.Time) error {
+	return s.AppendWidgetHistory(ctx, AppendWidgetHistoryParams{
+		Widget:    widget,
+		Variant:   variant,
+		Data:      data,
+		FetchedAt: fetchedAt.UTC(),
+	})
+}
+
+// ListHistory calls fn for each value recorded since the given time, oldest first.
+func (s *Store) ListHistory(ctx context.Context, widget, variant string, since time.Time, fn func(data []byte, fetchedAt time.Time) error) error {
+	rows, err := s.ListWidgetHistory(ctx, ListWidgetHistoryParams{
+		Widget:    widget,
+		Variant:   variant,
+		FetchedAt: since.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := fn(r.Data, r.FetchedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneHistory deletes a widget's values recorded before the cutoff (all variants).
+func (s *Store) PruneHistory(ctx context.Context, widget string, before time.Time) (int64, error) {
+	return s.PruneWidgetHistory(ctx, PruneWidgetHistoryParams{Widget: widget, FetchedAt: before.UTC()})
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	// Registers the "sqlite3" database/sql driver.
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/patrickneise/dashboard/internal/migrate"
+	"github.com/patrickneise/dashboard/migrations"
+)
+
+// Store bundles the database handle with the generated queries.
+type Store struct {
+	*Queries
+	DB *sql.DB
+}
+
+// Open opens (creating if needed) the SQLite database at path. With autoMigrate it
+// applies pending migrations; otherwise it refuses to start on an outdated schema so
+// `dashboard migrate up` can be run as a separate deploy step.
+func Open(ctx context.Context, path string, autoMigrate bool) (*Store, error) {
+	db, err := OpenDB(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSchema(ctx, db, autoMigrate); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Store{Queries: New(db), DB: db}, nil
+}
+
+// OpenDB opens the database without touching the schema (for the migrate command).
+func OpenDB(ctx context.Context, path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, errors.New("store: database path is empty")
+	}
+
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("store: open %s: %w", path, err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("store: ping %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Migrator returns a migrator for the embedded migrations.
+func Migrator(db *sql.DB) (*migrate.Migrator, error) {
+	return migrate.New(db, migrations.FS)
+}
+
+func checkSchema(ctx context.Context, db *sql.DB, autoMigrate bool) error {
+	m, err := Migrator(db)
+	if err != nil {
+		return err
+	}
+
+	if autoMigrate {
+		if _, err := m.Up(ctx); err != nil {
+			return fmt.Errorf("store: %w", err)
+		}
+		return nil
+	}
+
+	st, err := m.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	if st.Dirty || len(st.Pending) > 0 {
+		return fmt.Errorf("store: schema at version %d has %d pending migration(s); run `dashboard migrate up`",
+			st.Version, len(st.Pending))
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// InTx runs fn inside a transaction, committing if it returns nil and rolling back otherwise.
+func (s *Store) InTx(ctx context.Context, fn func(q *Queries) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(s.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,40 @@
+package components
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Sparkline viewBox; the SVG stretches to its container.
+const (
+	sparkWidth  = 100.0
+	sparkHeight = 24.0
+	sparkPad    = 1.0
+)
+
+var sparkViewBox = "0 0 " + strconv.FormatFloat(sparkWidth, 'f', -1, 64) + " " + strconv.FormatFloat(sparkHeight, 'f', -1, 64)
+
+// sparklinePoints scales values into the viewBox as a polyline "x,y x,y ..." string.
+func sparklinePoints(values []float64) string {
+	lo, hi := slices.Min(values), slices.Max(values)
+	span := hi - lo
+	if span == 0 {
+		span = 1 // flat line through the middle
+		lo -= 0.5
+	}
+
+	step := sparkWidth / float64(len(values)-1)
+	var b strings.Builder
+	for i, v := range values {
+		x := float64(i) * step
+		y := sparkPad + (sparkHeight-2*sparkPad)*(1-(v-lo)/span)
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.FormatFloat(x, 'f', 2, 64))
+		b.WriteByte(',')
+		b.WriteString(strconv.FormatFloat(y, 'f', 2, 64))
+	}
+	return b.String()
+}
@@ -1,9 +1,17 @@
 package widgetkit
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/a-h/templ"
@@ -15,7 +23,11 @@ import (
 type Handler[T any] struct {
 	Name  string
 	TTL   time.Duration
-	Cache *cache.TTL[T]
+	Cache *cache.Keyed[T]
+
+	// Variant identifies the effective options for a request (e.g. per-user location).
+	// Values are cached per distinct variant; nil means one shared entry.
+	Variant func(ctx context.Context) string
 
 	Fetch  func(ctx context.Context) (T, error)
 	Render func(data T) templ.Component
@@ -23,18 +35,64 @@ type Handler[T any] struct {
 
 	MarkStale func(v T, staleBy time.Duration) T
 
+	// Snapshots, when set, is consulted before fetching: values written by the worker
+	// are served until they expire, and live fetches only fill the gaps.
+	Snapshots SnapshotStore
+
+	// History, when set, records every fetched value and prunes entries older than
+	// Retention (zero keeps everything). Trend then gets the values recorded within
+	// TrendWindow, oldest first, to fill in trend fields (sparklines, rank changes).
+	History     HistoryStore
+	Retention   time.Duration
+	TrendWindow time.Duration
+	Trend       func(v T, past []Point[T]) T
+
 	Log *slog.Logger
 }
 
-func (h Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+// DataHandler is implemented by widget handlers that can also serve their view model as
+// JSON (see Handler.ServeJSON). The server mounts these under /api/widgets/<key>.
+type DataHandler interface {
+	ServeJSON(w http.ResponseWriter, r *http.Request)
 
-	now := time.Now()
-	reqID := middleware.GetReqID(r.Context())
-	hx := r.Header.Get("HX-Request") == "true"
-	path := r.URL.Path
+	// JSONType is the Go type of the body ServeJSON writes on success (used to generate
+	// the OpenAPI description).
+	JSONType() reflect.Type
+
+	// LoadData resolves the same envelope outside of an HTTP request (CLI, jobs).
+	LoadData(ctx context.Context) (any, error)
+}
+
+// Envelope is the JSON shape of a widget's data.
+type Envelope[T any] struct {
+	Widget    string     `json:"widget"`
+	Data      T          `json:"data"`
+	Stale     bool       `json:"stale"`
+	StaleBy   string     `json:"stale_by,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
 
-	log := h.reqLogger(reqID, hx, path)
+// ErrorEnvelope is returned (with 502) when there is no data to serve.
+type ErrorEnvelope struct {
+	Widget string `json:"widget"`
+	Error  string `json:"error"`
+}
+
+var errNoData = errors.New("widget data unavailable")
+
+// result is one resolved widget value plus its caching metadata.
+type result[T any] struct {
+	value   T
+	maxAge  time.Duration // 0 means "revalidate every time"
+	exp     time.Time     // zero when not cached
+	stale   bool
+	staleBy time.Duration
+}
+
+// load returns fresh cached data, refreshes it, or falls back to stale data when the
+// refresh fails. It returns errNoData-wrapping errors only when nothing can be served.
+func (h Handler[T]) load(ctx context.Context, log *slog.Logger) (result[T], error) {
+	now := time.Now()
 
 	var (
 		cached     T
@@ -42,44 +100,93 @@ func (h Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		cacheState cache.State = cache.Miss
 	)
 
+	variant := h.variant(ctx)
+
 	// Cached version is current
 	if h.Cache != nil {
-		cached, cacheExp, cacheState = h.Cache.Get(now)
+		cached, cacheExp, cacheState = h.Cache.Get(variant, now)
 		if cacheState == cache.Fresh {
-			if err := h.Render(cached).Render(r.Context(), w); err != nil {
-				h.renderError(w, r, err)
+			return result[T]{value: cached, maxAge: cacheExp.Sub(now), exp: cacheExp}, nil
+		}
+	}
+
+	// Snapshot written by the worker
+	if h.Snapshots != nil {
+		if snap, exp, ok := h.loadSnapshot(ctx, variant, log); ok {
+			if now.Before(exp) {
+				if h.Cache != nil {
+					h.Cache.Set(variant, snap, exp)
+				}
+				return result[T]{value: snap, maxAge: exp.Sub(now), exp: exp}, nil
+			}
+			// Expired, but still usable as a stale fallback if it beats memory.
+			if cacheState == cache.Miss || exp.After(cacheExp) {
+				cached, cacheExp, cacheState = snap, exp, cache.Stale
 			}
-			return
 		}
 	}
 
 	// Cache is stale or missing: attempt refresh
-	v, err := h.Fetch(r.Context())
+	// With snapshots, history is the worker's to record (see fetch).
+	v, err := h.fetch(ctx, variant, h.Snapshots == nil, log)
 	if err != nil {
 		// If we have stale data, serve it instead of erroring the widget.
 		if cacheState == cache.Stale {
 			staleBy := now.Sub(cacheExp)
 			if log != nil {
 				log.Warn("widget_fetch_failed_serving_stale",
+					slog.String("variant", variant),
 					slog.Duration("stale_by", staleBy),
 					slog.Any("err", err))
 			}
-			w.Header().Set("X-Widget-Stale", "true")
-			toRender := cached
+			toServe := cached
 			if h.MarkStale != nil {
-				toRender = h.MarkStale(cached, staleBy)
-			}
-			if err := h.Render(toRender).Render(r.Context(), w); err != nil {
-				h.renderError(w, r, err)
+				toServe = h.MarkStale(cached, staleBy)
 			}
-			return
+			// Stale data must be revalidated so the next poll retries the refresh.
+			return result[T]{value: toServe, exp: cacheExp, stale: true, staleBy: staleBy}, nil
 		}
 
 		// No cache to fall back to
 		if log != nil {
-			log.Error("widget_fetch_failed", slog.Any("err", err))
+			log.Error("widget_fetch_failed", slog.String("variant", variant), slog.Any("err", err))
 		}
+		return result[T]{}, errors.Join(errNoData, err)
+	}
+
+	// Refresh succeeded: update cache
+	res := result[T]{value: v}
+	if h.Cache != nil && h.TTL > 0 {
+		res.exp = now.Add(h.TTL)
+		res.maxAge = h.TTL
+		h.Cache.Set(variant, v, res.exp)
+	}
+	return res, nil
+}
+
+func (h Handler[T]) variant(ctx context.Context) string {
+	if h.Variant == nil {
+		return ""
+	}
+	return h.Variant(ctx)
+}
+
+// ServeHTTP renders the widget as an HTML fragment, or as JSON when the client asks for
+// application/json (and not HTML).
+func (h Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	if wantsJSON(r) {
+		h.ServeJSON(w, r)
+		return
+	}
 
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	log := h.requestLogger(r)
+
+	res, err := h.load(r.Context(), log)
+	if err != nil {
+		w.Header().Set("Cache-Control", "no-store")
 		w.WriteHeader(http.StatusBadGateway)
 		if h.Error != nil {
 			_ = h.Error(err).Render(r.Context(), w)
@@ -87,28 +194,142 @@ func (h Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Refresh succeeded: update cache + render
-	if h.Cache != nil && h.TTL > 0 {
-		h.Cache.Set(v, now.Add(h.TTL))
+	if res.stale {
+		w.Header().Set("X-Widget-Stale", "true")
 	}
 
-	if err := h.Render(v).Render(r.Context(), w); err != nil {
+	var buf bytes.Buffer
+	if err := h.Render(res.value).Render(r.Context(), &buf); err != nil {
 		h.renderError(w, r, err)
+		return
 	}
+	writeWithValidators(w, r, buf.Bytes(), res.maxAge)
 }
 
-func (h Handler[T]) renderError(w http.ResponseWriter, r *http.Request, err error) {
-	reqID := middleware.GetReqID(r.Context())
-	hx := r.Header.Get("HX-Request") == "true"
-	path := r.URL.Path
+// ServeJSON writes the widget's view model wrapped in an Envelope with stale/expiry
+// metadata. It shares the cache (and refresh behavior) with the HTML fragment.
+func (h Handler[T]) ServeJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	log := h.requestLogger(r)
+
+	res, err := h.load(r.Context(), log)
+	if err != nil {
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(ErrorEnvelope{Widget: h.Name, Error: errNoData.Error()})
+		return
+	}
+
+	if res.stale {
+		w.Header().Set("X-Widget-Stale", "true")
+	}
+
+	body, err := json.Marshal(h.envelope(res))
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+	writeWithValidators(w, r, append(body, '\n'), res.maxAge)
+}
+
+// LoadData returns the widget's Envelope[T] for ctx, sharing the cache with ServeHTTP.
+func (h Handler[T]) LoadData(ctx context.Context) (any, error) {
+	res, err := h.load(ctx, h.widgetLogger())
+	if err != nil {
+		return nil, err
+	}
+	return h.envelope(res), nil
+}
+
+func (h Handler[T]) envelope(res result[T]) Envelope[T] {
+	env := Envelope[T]{Widget: h.Name, Data: res.value, Stale: res.stale}
+	if res.stale {
+		env.StaleBy = res.staleBy.Round(time.Second).String()
+	}
+	if !res.exp.IsZero() {
+		exp := res.exp.UTC()
+		env.ExpiresAt = &exp
+	}
+	return env
+}
+
+// JSONType reports Envelope[T], the success body of ServeJSON.
+func (h Handler[T]) JSONType() reflect.Type {
+	return reflect.TypeFor[Envelope[T]]()
+}
+
+// writeWithValidators writes body with an ETag over its bytes and a Cache-Control
+// max-age matching the remaining cache lifetime. A matching If-None-Match gets a
+// bodiless 304, so polls of unchanged widgets cost only the (cached) render.
+func writeWithValidators(w http.ResponseWriter, r *http.Request, body []byte, maxAge time.Duration) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl(maxAge))
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	_, _ = w.Write(body)
+}
+
+// cacheControl is private because fragments depend on the signed-in user.
+func cacheControl(maxAge time.Duration) string {
+	secs := int(maxAge / time.Second)
+	if secs <= 0 {
+		return "private, no-cache"
+	}
+	return "private, max-age=" + strconv.Itoa(secs)
+}
+
+// etagMatches implements the weak comparison If-None-Match calls for, so validators
+// weakened by the compression middleware (W/"...") still match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
 
-	log := h.reqLogger(reqID, hx, path)
+// wantsJSON reports whether the Accept header asks for JSON rather than HTML.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+func (h Handler[T]) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	log := h.requestLogger(r)
 	if log != nil {
 		log.Error("widget_render_failed", slog.Any("err", err))
 	}
+	w.Header().Set("Cache-Control", "no-store")
 	http.Error(w, "render error", http.StatusInternalServerError)
 }
 
+func (h Handler[T]) requestLogger(r *http.Request) *slog.Logger {
+	reqID := middleware.GetReqID(r.Context())
+	hx := r.Header.Get("HX-Request") == "true"
+	return h.reqLogger(reqID, hx, r.URL.Path)
+}
+
+// widgetLogger is for work outside a request (CLI, worker).
+func (h Handler[T]) widgetLogger() *slog.Logger {
+	if h.Log == nil {
+		return nil
+	}
+	return h.Log.With(slog.String("widget", h.Name))
+}
+
 func (h Handler[T]) reqLogger(reqID string, hx bool, path string) *slog.Logger {
 	if h.Log == nil {
 		return nil
@@ -0,0 +1,96 @@
+package widgetkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// HistoryStore keeps every fetched value, not just the latest, so widgets can show
+// trends. *store.Store implements it.
+type HistoryStore interface {
+	AppendHistory(ctx context.Context, widget, variant string, data []byte, fetchedAt time.Time) error
+	ListHistory(ctx context.Context, widget, variant string, since time.Time, fn func(data []byte, fetchedAt time.Time) error) error
+	PruneHistory(ctx context.Context, widget string, before time.Time) (int64, error)
+}
+
+// Point is one historical value of a widget.
+type Point[T any] struct {
+	At    time.Time
+	Value T
+}
+
+// fetch wraps Fetch with history: when record is set the new value is appended (and old
+// values pruned per Retention), then Trend derives trend fields from the values seen in
+// TrendWindow. History failures are logged and never fail the fetch.
+//
+// With a SnapshotStore only Sync records, so serve and the worker don't both write each
+// point. A value is also skipped when another was recorded within the last TTL/2.
+func (h Handler[T]) fetch(ctx context.Context, variant string, record bool, log *slog.Logger) (T, error) {
+	v, err := h.Fetch(ctx)
+	if err != nil || h.History == nil {
+		return v, err
+	}
+
+	now := time.Now()
+	var past []Point[T]
+	if h.Trend != nil && h.TrendWindow > 0 {
+		past = h.listHistory(ctx, variant, now.Add(-h.TrendWindow), log)
+	}
+
+	if record && !h.recordedSince(ctx, variant, now.Add(-h.TTL/2), log) {
+		h.recordHistory(ctx, variant, v, now, log)
+	}
+
+	if h.Trend != nil {
+		v = h.Trend(v, past)
+	}
+	return v, nil
+}
+
+var errFound = errors.New("found")
+
+// recordedSince reports whether a value for variant was recorded after since. Read
+// errors count as "no", so history keeps being written.
+func (h Handler[T]) recordedSince(ctx context.Context, variant string, since time.Time, log *slog.Logger) bool {
+	err := h.History.ListHistory(ctx, h.Name, variant, since, func([]byte, time.Time) error {
+		return errFound
+	})
+	if err != nil && !errors.Is(err, errFound) && log != nil {
+		log.Warn("widget_history_read_failed", slog.String("variant", variant), slog.Any("err", err))
+	}
+	return errors.Is(err, errFound)
+}
+
+func (h Handler[T]) listHistory(ctx context.Context, variant string, since time.Time, log *slog.Logger) []Point[T] {
+	var past []Point[T]
+	err := h.History.ListHistory(ctx, h.Name, variant, since, func(data []byte, at time.Time) error {
+		var p Point[T]
+		if err := json.Unmarshal(data, &p.Value); err != nil {
+			// Skip rows written by an older, incompatible view model.
+			return nil
+		}
+		p.At = at
+		past = append(past, p)
+		return nil
+	})
+	if err != nil && log != nil {
+		log.Warn("widget_history_read_failed", slog.String("variant", variant), slog.Any("err", err))
+	}
+	return past
+}
+
+func (h Handler[T]) recordHistory(ctx context.Context, variant string, v T, now time.Time, log *slog.Logger) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = h.History.AppendHistory(ctx, h.Name, variant, data, now)
+	}
+	if err == nil && h.Retention > 0 {
+		_, err = h.History.PruneHistory(ctx, h.Name, now.Add(-h.Retention))
+	}
+	if err != nil && log != nil {
+		log.Warn("widget_history_write_failed", slog.String("variant", variant), slog.Any("err", err))
+	}
+}
@@ -15,6 +15,14 @@ type Spec struct {
 
 	Trigger string
 	Class   string
+
+	// Groups restricts the widget to members of any of these groups. Empty means everyone.
+	Groups []string
+
+	// Store marks widgets that read the database; PerUser ones also need a signed-in
+	// user. The CLI opens the database (and takes -user) for these.
+	Store   bool
+	PerUser bool
 }
 
 type Registry struct {
@@ -0,0 +1,24 @@
+package widgetkit
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Mounter is implemented by widgets that handle more than the GET of their fragment,
+// e.g. HTMX forms. Mount receives a router scoped to /widgets/<key> (auth and group
+// checks already applied) and must not register GET "/", which stays the fragment.
+//
+// The convention for write routes: validate, write, call Invalidate, then respond with
+// the re-rendered fragment (ServeHTTP) so HTMX swaps the updated widget in place.
+type Mounter interface {
+	Mount(r chi.Router)
+}
+
+// Invalidate drops the cached value for ctx's variant so the next load refetches.
+func (h Handler[T]) Invalidate(ctx context.Context) {
+	if h.Cache != nil {
+		h.Cache.Delete(h.variant(ctx))
+	}
+}
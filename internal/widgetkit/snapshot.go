@@ -0,0 +1,74 @@
+package widgetkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// SnapshotStore persists serialized widget values so a separate worker process can do the
+// fetching while web processes only read. *store.Store implements it.
+type SnapshotStore interface {
+	LoadSnapshot(ctx context.Context, widget, variant string) (data []byte, expiresAt time.Time, found bool, err error)
+	SaveSnapshot(ctx context.Context, widget, variant string, data []byte, fetchedAt, expiresAt time.Time) error
+}
+
+// Syncer is implemented by handlers the worker can refresh into a SnapshotStore.
+type Syncer interface {
+	// Sync fetches the default variant (no user preferences) and saves it.
+	Sync(ctx context.Context) error
+
+	// SyncInterval is how often Sync should run to keep snapshots from expiring. Zero
+	// means there is nothing to sync (no snapshot store).
+	SyncInterval() time.Duration
+}
+
+var errNoSnapshotStore = errors.New("widgetkit: handler has no snapshot store")
+
+func (h Handler[T]) Sync(ctx context.Context) error {
+	if h.Snapshots == nil {
+		return errNoSnapshotStore
+	}
+
+	variant := h.variant(ctx)
+	v, err := h.fetch(ctx, variant, true, h.widgetLogger())
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return h.Snapshots.SaveSnapshot(ctx, h.Name, variant, data, now, now.Add(h.TTL))
+}
+
+// SyncInterval refreshes at 3/4 of the TTL so readers see a fresh snapshot as long as
+// the worker keeps up.
+func (h Handler[T]) SyncInterval() time.Duration {
+	if h.Snapshots == nil {
+		return 0
+	}
+	return max(h.TTL*3/4, 10*time.Second)
+}
+
+// loadSnapshot reads and decodes the stored snapshot for variant. Errors are logged and
+// reported as "not found" so the handler falls back to a live fetch.
+func (h Handler[T]) loadSnapshot(ctx context.Context, variant string, log *slog.Logger) (T, time.Time, bool) {
+	var v T
+
+	data, exp, found, err := h.Snapshots.LoadSnapshot(ctx, h.Name, variant)
+	if err == nil && found {
+		err = json.Unmarshal(data, &v)
+	}
+	if err != nil {
+		if log != nil {
+			log.Warn("widget_snapshot_unreadable", slog.String("variant", variant), slog.Any("err", err))
+		}
+		return v, time.Time{}, false
+	}
+	return v, exp, found
+}
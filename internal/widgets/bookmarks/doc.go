@@ -0,0 +1,4 @@
+// Package bookmarks implements a per-user quick links widget stored in SQLite. It is the
+// reference for widgets with write routes (see widgetkit.Mounter): add, edit and delete
+// are HTMX forms that respond with the re-rendered widget.
+package bookmarks
@@ -0,0 +1,74 @@
+package bookmarks
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+const maxTags = 10
+
+// Form holds the add/edit form values as submitted, so invalid input can be shown
+// again with an error.
+type Form struct {
+	ID    int64 // zero when adding
+	Group string
+	Name  string
+	URL   string
+	Icon  string
+	Tags  string
+
+	Error string
+}
+
+func formFromBookmark(b store.Bookmark) Form {
+	return Form{ID: b.ID, Group: b.GroupName, Name: b.Name, URL: b.Url, Icon: b.Icon, Tags: b.Tags}
+}
+
+func formFromRequest(r *http.Request) Form {
+	return Form{
+		Group: strings.TrimSpace(r.PostFormValue("group")),
+		Name:  strings.TrimSpace(r.PostFormValue("name")),
+		URL:   strings.TrimSpace(r.PostFormValue("url")),
+		Icon:  strings.TrimSpace(r.PostFormValue("icon")),
+		Tags:  r.PostFormValue("tags"),
+	}
+}
+
+// validate checks the form and normalizes the tags; the returned message is shown
+// to the user as-is.
+func (f *Form) validate() string {
+	switch {
+	case f.Name == "":
+		return "Name is required."
+	case utf8.RuneCountInString(f.Name) > 100:
+		return "Name must be at most 100 characters."
+	case utf8.RuneCountInString(f.Group) > 50:
+		return "Group must be at most 50 characters."
+	}
+
+	u, err := url.Parse(f.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "URL must start with http:// or https://."
+	}
+
+	if f.Icon != "" && !isImageURL(f.Icon) && utf8.RuneCountInString(f.Icon) > 8 {
+		return "Icon must be an https:// image URL or a short emoji/text."
+	}
+
+	var tags []string
+	for _, t := range splitTags(strings.ToLower(f.Tags)) {
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > maxTags {
+		return "Use at most 10 tags."
+	}
+	f.Tags = strings.Join(tags, ",")
+	return ""
+}
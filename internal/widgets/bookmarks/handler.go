@@ -0,0 +1,227 @@
+package bookmarks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/internal/ui/components"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+type Options struct {
+	Queries *store.Queries
+	Log     *slog.Logger
+}
+
+var (
+	errNoStore = errors.New("bookmarks: no database configured")
+	errNoUser  = errors.New("bookmarks: no signed-in user")
+)
+
+// Widget serves the bookmarks fragment (via the embedded widgetkit.Handler) and the
+// add/edit/delete routes.
+type Widget struct {
+	widgetkit.Handler[WidgetViewModel]
+
+	q   *store.Queries
+	log *slog.Logger
+}
+
+func NewWidgetHandler(opts Options) *Widget {
+	log := opts.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	w := &Widget{q: opts.Queries, log: log}
+	w.Handler = widgetkit.Handler[WidgetViewModel]{
+		Name: "bookmarks",
+		Log:  opts.Log,
+
+		// No cache: reads are one indexed query and writes must show up immediately
+		// (in every serve process).
+		Fetch: w.load,
+
+		Render: func(vm WidgetViewModel) templ.Component {
+			return WidgetView(vm, nil)
+		},
+
+		Error: func(_ error) templ.Component {
+			return components.WidgetError("Bookmarks", "/widgets/bookmarks")
+		},
+	}
+	return w
+}
+
+func (w *Widget) Mount(r chi.Router) {
+	r.Get("/new", w.newForm)
+	r.Post("/", w.create)
+	r.Get("/{id}/edit", w.editForm)
+	r.Put("/{id}", w.update)
+	r.Delete("/{id}", w.remove)
+}
+
+func (w *Widget) load(ctx context.Context) (WidgetViewModel, error) {
+	if w.q == nil {
+		return WidgetViewModel{}, errNoStore
+	}
+	u, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return WidgetViewModel{}, errNoUser
+	}
+	rows, err := w.q.ListBookmarks(ctx, u.ID)
+	if err != nil {
+		return WidgetViewModel{}, err
+	}
+	return BuildViewModel(rows), nil
+}
+
+func (w *Widget) newForm(rw http.ResponseWriter, r *http.Request) {
+	w.renderForm(rw, r, Form{})
+}
+
+func (w *Widget) editForm(rw http.ResponseWriter, r *http.Request) {
+	u, _ := auth.UserFromContext(r.Context())
+	id, ok := bookmarkID(rw, r)
+	if !ok {
+		return
+	}
+
+	b, err := w.q.GetBookmark(r.Context(), store.GetBookmarkParams{ID: id, UserID: u.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(rw, r)
+		return
+	}
+	if err != nil {
+		w.fail(rw, r, "bookmark_load_failed", err)
+		return
+	}
+	w.renderForm(rw, r, formFromBookmark(b))
+}
+
+func (w *Widget) create(rw http.ResponseWriter, r *http.Request) {
+	u, _ := auth.UserFromContext(r.Context())
+
+	f := formFromRequest(r)
+	if msg := f.validate(); msg != "" {
+		f.Error = msg
+		w.renderForm(rw, r, f)
+		return
+	}
+
+	err := w.q.CreateBookmark(r.Context(), store.CreateBookmarkParams{
+		UserID:    u.ID,
+		GroupName: f.Group,
+		Name:      f.Name,
+		Url:       f.URL,
+		Icon:      f.Icon,
+		Tags:      f.Tags,
+	})
+	if err != nil {
+		w.fail(rw, r, "bookmark_create_failed", err)
+		return
+	}
+	w.done(rw, r)
+}
+
+func (w *Widget) update(rw http.ResponseWriter, r *http.Request) {
+	u, _ := auth.UserFromContext(r.Context())
+	id, ok := bookmarkID(rw, r)
+	if !ok {
+		return
+	}
+
+	f := formFromRequest(r)
+	f.ID = id
+	if msg := f.validate(); msg != "" {
+		f.Error = msg
+		w.renderForm(rw, r, f)
+		return
+	}
+
+	n, err := w.q.UpdateBookmark(r.Context(), store.UpdateBookmarkParams{
+		GroupName: f.Group,
+		Name:      f.Name,
+		Url:       f.URL,
+		Icon:      f.Icon,
+		Tags:      f.Tags,
+		ID:        id,
+		UserID:    u.ID,
+	})
+	if err != nil {
+		w.fail(rw, r, "bookmark_update_failed", err)
+		return
+	}
+	if n == 0 {
+		http.NotFound(rw, r)
+		return
+	}
+	w.done(rw, r)
+}
+
+func (w *Widget) remove(rw http.ResponseWriter, r *http.Request) {
+	u, _ := auth.UserFromContext(r.Context())
+	id, ok := bookmarkID(rw, r)
+	if !ok {
+		return
+	}
+
+	n, err := w.q.DeleteBookmark(r.Context(), store.DeleteBookmarkParams{ID: id, UserID: u.ID})
+	if err != nil {
+		w.fail(rw, r, "bookmark_delete_failed", err)
+		return
+	}
+	if n == 0 {
+		http.NotFound(rw, r)
+		return
+	}
+	w.done(rw, r)
+}
+
+// done finishes a write: drop cached data and answer with the updated widget.
+func (w *Widget) done(rw http.ResponseWriter, r *http.Request) {
+	w.Invalidate(r.Context())
+	w.ServeHTTP(rw, r)
+}
+
+// renderForm shows the widget with the add/edit form open. Validation errors are sent
+// with 200 because HTMX does not swap 4xx responses by default.
+func (w *Widget) renderForm(rw http.ResponseWriter, r *http.Request, f Form) {
+	vm, err := w.load(r.Context())
+	if err != nil {
+		w.fail(rw, r, "bookmarks_load_failed", err)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	if err := WidgetView(vm, &f).Render(r.Context(), rw); err != nil {
+		w.log.ErrorContext(r.Context(), "bookmarks_render_failed", slog.Any("err", err))
+	}
+}
+
+func (w *Widget) fail(rw http.ResponseWriter, r *http.Request, msg string, err error) {
+	w.log.ErrorContext(r.Context(), msg, slog.Any("err", err))
+	http.Error(rw, "something went wrong", http.StatusInternalServerError)
+}
+
+func bookmarkID(rw http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.NotFound(rw, r)
+		return 0, false
+	}
+	return id, true
+}
+
+func linkPath(id int64) string {
+	return "/widgets/bookmarks/" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,83 @@
+package bookmarks
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+type Link struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Icon    string   `json:"icon,omitempty"`     // emoji/short text, when set
+	IconURL string   `json:"icon_url,omitempty"` // image: explicit icon or the site's favicon
+	Tags    []string `json:"tags,omitempty"`
+}
+
+type Group struct {
+	Name  string `json:"name"`
+	Links []Link `json:"links"`
+}
+
+type WidgetViewModel struct {
+	Groups []Group `json:"groups"`
+}
+
+// BuildViewModel groups rows (already ordered by group) for rendering.
+func BuildViewModel(rows []store.Bookmark) WidgetViewModel {
+	var vm WidgetViewModel
+	for _, b := range rows {
+		if n := len(vm.Groups); n == 0 || !strings.EqualFold(vm.Groups[n-1].Name, b.GroupName) {
+			vm.Groups = append(vm.Groups, Group{Name: b.GroupName})
+		}
+		g := &vm.Groups[len(vm.Groups)-1]
+		g.Links = append(g.Links, toLink(b))
+	}
+	return vm
+}
+
+func toLink(b store.Bookmark) Link {
+	l := Link{ID: b.ID, Name: b.Name, URL: b.Url, Tags: splitTags(b.Tags)}
+	switch {
+	case isImageURL(b.Icon):
+		l.IconURL = b.Icon
+	case b.Icon != "":
+		l.Icon = b.Icon
+	default:
+		l.IconURL = faviconURL(b.Url)
+	}
+	return l
+}
+
+// faviconURL guesses /favicon.ico for https sites; the CSP only allows https images.
+func faviconURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return ""
+	}
+	return "https://" + u.Host + "/favicon.ico"
+}
+
+func isImageURL(s string) bool {
+	return strings.HasPrefix(s, "https://")
+}
+
+// initial is shown when a link has neither an icon nor a usable favicon.
+func initial(name string) string {
+	for _, r := range name {
+		return strings.ToUpper(string(r))
+	}
+	return "?"
+}
+
+func splitTags(v string) []string {
+	var out []string
+	for _, t := range strings.Split(v, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
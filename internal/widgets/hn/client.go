@@ -20,19 +20,29 @@ func NewClient(h *httpx.Client) *Client {
 	return &Client{http: h}
 }
 
-// TopStories returns a list of item IDs in rank order.
-func (c *Client) TopStories(ctx context.Context) ([]int64, error) {
+// Stories returns the item IDs on a list in rank order.
+func (c *Client) Stories(ctx context.Context, list List) ([]int64, error) {
+	endpoint, ok := listEndpoints[list]
+	if !ok {
+		return nil, fmt.Errorf("hn: unknown list %q", list)
+	}
+
 	var ids []int64
-	if err := c.http.GetJSON(ctx, fmt.Sprintf("%s/topstories.json", baseURL), &ids); err != nil {
+	if err := c.http.GetJSON(ctx, fmt.Sprintf("%s/%s.json", baseURL, endpoint), &ids); err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
+// Item fetches one item. Purged items (the API answers null) are an error, so they are
+// neither cached nor shown.
 func (c *Client) Item(ctx context.Context, id int64) (*Item, error) {
 	var it Item
 	if err := c.http.GetJSON(ctx, fmt.Sprintf("%s/item/%d.json", baseURL, id), &it); err != nil {
 		return nil, err
 	}
+	if it.ID == 0 {
+		return nil, fmt.Errorf("hn: item %d not found", id)
+	}
 	return &it, nil
 }
@@ -0,0 +1,132 @@
+package hn
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/cache"
+	"github.com/patrickneise/dashboard/internal/markdown"
+)
+
+// Comment is one top-level comment, its HTML already sanitized.
+type Comment struct {
+	ID   int64
+	By   string
+	Age  string
+	HTML string
+}
+
+// CommentsViewModel is the expanded comment preview under a story.
+type CommentsViewModel struct {
+	StoryID  int64
+	Comments []Comment
+	More     int // top-level comments beyond the ones fetched
+	Err      string
+}
+
+// Mount adds the lazily loaded comment previews (GET /{id}/comments).
+func (w *Widget) Mount(r chi.Router) {
+	r.Get("/{id}/comments", w.showComments)
+}
+
+func (w *Widget) showComments(rw http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.NotFound(rw, r)
+		return
+	}
+
+	vm, err := w.loadComments(r.Context(), id)
+	if err != nil {
+		w.log.WarnContext(r.Context(), "hn_comments_failed", slog.Int64("id", id), slog.Any("err", err))
+		// htmx doesn't swap error responses, so the message goes out with a 200.
+		vm = CommentsViewModel{StoryID: id, Err: "Comments are unavailable right now."}
+	}
+	w.render(rw, r, commentsView(vm))
+}
+
+// maxCommentScan bounds how many top-level comments one expand may fetch while looking
+// for usable ones.
+const maxCommentScan = 30
+
+// loadComments returns the story's first top-level comments, cached per story for the
+// item TTL. Deleted, dead and empty comments, and ones that fail to load, are skipped
+// and more fetched in their place, in batches sized to the shortfall.
+func (w *Widget) loadComments(ctx context.Context, id int64) (CommentsViewModel, error) {
+	key := strconv.FormatInt(id, 10)
+	now := time.Now()
+	if vm, _, state := w.comments.Get(key, now); state == cache.Fresh {
+		return vm, nil
+	}
+
+	story := w.items.get(ctx, []int64{id})
+	if story.items[0] == nil {
+		return CommentsViewModel{}, story.err
+	}
+
+	kids := story.items[0].Kids
+	ids := kids[:min(len(kids), maxCommentScan)]
+	vm := CommentsViewModel{StoryID: id}
+
+	var attempted, failed int
+	var err error
+	for len(ids) > 0 && len(vm.Comments) < w.commentCount {
+		batch := min(w.commentCount-len(vm.Comments), len(ids))
+		res := w.items.get(ctx, ids[:batch])
+		ids = ids[batch:]
+
+		attempted += batch
+		failed += res.failed
+		if res.err != nil {
+			err = res.err
+		}
+
+		for _, it := range res.items {
+			if it == nil || it.Deleted || it.Dead || it.Text == "" {
+				continue
+			}
+			vm.Comments = append(vm.Comments, Comment{
+				ID:   it.ID,
+				By:   it.By,
+				Age:  relativeAge(now, it.Time),
+				HTML: markdown.Sanitize(it.Text),
+			})
+		}
+		if res.failed == batch {
+			// The whole batch failed: the API is likely down, so stop here.
+			break
+		}
+	}
+	if failed == attempted && failed > 0 {
+		return CommentsViewModel{}, err
+	}
+	vm.More = len(kids) - attempted
+
+	// Partial results aren't cached, so the next expand retries the missing ones.
+	if failed == 0 {
+		w.comments.Set(key, vm, now.Add(w.items.ttl))
+	}
+	return vm, nil
+}
+
+func (w *Widget) render(rw http.ResponseWriter, r *http.Request, c templ.Component) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	if err := c.Render(r.Context(), rw); err != nil {
+		w.log.ErrorContext(r.Context(), "hn_render_failed", slog.Any("err", err))
+	}
+}
+
+func commentsDOMID(id int64) string {
+	return "hn-comments-" + strconv.FormatInt(id, 10)
+}
+
+func commentsPath(id int64) string {
+	return "/widgets/hn/" + strconv.FormatInt(id, 10) + "/comments"
+}
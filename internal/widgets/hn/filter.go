@@ -0,0 +1,100 @@
+package hn
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Filter drops stories before they count towards the widget's story count. Keywords
+// match whole words in the title, case-insensitively. The zero value keeps everything.
+type Filter struct {
+	Include  []string      // keep only titles with one of these (empty: all)
+	Exclude  []string      // drop titles with any of these
+	Domains  []string      // drop links to these domains and their subdomains
+	MinScore int           // drop stories below this score (jobs have none and are kept)
+	MaxAge   time.Duration // drop older stories (zero: no limit)
+}
+
+// Active reports whether the filter can drop anything.
+func (f Filter) Active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0 || len(f.Domains) > 0 || f.MinScore > 0 || f.MaxAge > 0
+}
+
+func (f Filter) keep(it *Item, now time.Time) bool {
+	if len(f.Include) > 0 && len(matchWords(it.Title, f.Include)) == 0 {
+		return false
+	}
+	if len(matchWords(it.Title, f.Exclude)) > 0 {
+		return false
+	}
+	if blockedDomain(it.URL, f.Domains) {
+		return false
+	}
+	if f.MinScore > 0 && it.Type != "job" && it.Score < f.MinScore {
+		return false
+	}
+	if f.MaxAge > 0 && now.Sub(time.Unix(it.Time, 0)) > f.MaxAge {
+		return false
+	}
+	return true
+}
+
+// matchWords returns the keywords that occur in s as whole words (so "go" matches
+// "Go 1.24" but not "Google"), in keyword order.
+func matchWords(s string, keywords []string) []string {
+	if len(keywords) == 0 {
+		return nil
+	}
+	lower := strings.ToLower(s)
+	var out []string
+	for _, kw := range keywords {
+		if containsWord(lower, strings.ToLower(kw)) {
+			out = append(out, kw)
+		}
+	}
+	return out
+}
+
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for from := 0; ; {
+		i := strings.Index(s[from:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		from = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func blockedDomain(raw string, domains []string) bool {
+	if raw == "" || len(domains) == 0 {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "www."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
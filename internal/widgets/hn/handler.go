@@ -2,13 +2,16 @@ package hn
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
-	"sync"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/a-h/templ"
 	"github.com/patrickneise/dashboard/internal/cache"
+	"github.com/patrickneise/dashboard/internal/prefs"
 	"github.com/patrickneise/dashboard/internal/ui/components"
 	"github.com/patrickneise/dashboard/internal/widgetkit"
 )
@@ -17,18 +20,65 @@ type Options struct {
 	Count int
 	TTL   time.Duration
 
+	// List is shown by default (ListTop when empty); Lists are the tabs offered (all
+	// lists when empty). Each list is cached separately.
+	List  List
+	Lists []List
+
+	// Filter is applied before truncating to Count (more stories are fetched to fill
+	// the count). Highlight marks stories whose titles mention these keywords.
+	Filter    Filter
+	Highlight []string
+
+	// ItemTTL is how long fetched stories are reused across refreshes (default 15m),
+	// so each refresh refetches the list but only the items that expired.
+	ItemTTL time.Duration
+
+	// Comments is how many top-level comments a story's expanded preview shows
+	// (default 3). Previews are cached per story for ItemTTL.
+	Comments int
+
+	// Snapshots, when set, serves values written by the worker; History records values
+	// for trends, kept for Retention (see widgetkit.Handler).
+	Snapshots widgetkit.SnapshotStore
+	History   widgetkit.HistoryStore
+	Retention time.Duration
+
 	Client *Client
 	Log    *slog.Logger
 }
 
-func NewWidgetHandler(opts Options) http.Handler {
-	count := opts.Count
-	if count <= 0 {
-		count = 10
+// Widget serves the HN fragment (via the embedded widgetkit.Handler), choosing the
+// list from the ?list= query parameter, and the comment previews (see Mount).
+type Widget struct {
+	widgetkit.Handler[WidgetViewModel]
+
+	lists []List
+
+	items        *itemCache
+	comments     cache.Keyed[CommentsViewModel]
+	commentCount int
+	log          *slog.Logger
+}
+
+func NewWidgetHandler(opts Options) *Widget {
+	defaultCount := clampCount(opts.Count)
+
+	defaultList := opts.List
+	if !defaultList.Valid() {
+		defaultList = ListTop
 	}
-	if count > 50 {
-		// keep it sane
-		count = 50
+	lists := opts.Lists
+	if len(lists) == 0 {
+		lists = AllLists
+	}
+
+	// countFor applies the signed-in user's story count over the configured default.
+	countFor := func(ctx context.Context) int {
+		if n := prefs.FromContext(ctx).HNCount; n > 0 {
+			return clampCount(n)
+		}
+		return defaultCount
 	}
 
 	ttl := opts.TTL
@@ -41,66 +91,69 @@ func NewWidgetHandler(opts Options) http.Handler {
 		opts.Client = NewClient(nil)
 	}
 
-	return widgetkit.Handler[WidgetViewModel]{
-		Name:  "hn",
-		TTL:   ttl,
-		Cache: &cache.TTL[WidgetViewModel]{},
-		Log:   opts.Log,
+	itemTTL := opts.ItemTTL
+	if itemTTL <= 0 {
+		itemTTL = 15 * time.Minute
+	}
+	items := newItemCache(opts.Client, itemTTL)
+
+	commentCount := opts.Comments
+	if commentCount <= 0 {
+		commentCount = 3
+	}
+
+	log := opts.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	w := &Widget{lists: lists, items: items, commentCount: commentCount, log: log}
+	w.Handler = widgetkit.Handler[WidgetViewModel]{
+		Name:      "hn",
+		TTL:       ttl,
+		Cache:     &cache.Keyed[WidgetViewModel]{},
+		Snapshots: opts.Snapshots,
+		Log:       opts.Log,
+
+		History:     opts.History,
+		Retention:   opts.Retention,
+		TrendWindow: trendWindow,
+		Trend:       withTrend,
+
+		Variant: func(ctx context.Context) string {
+			return string(listFrom(ctx, defaultList)) + ":" + strconv.Itoa(countFor(ctx))
+		},
 
 		Fetch: func(ctx context.Context) (WidgetViewModel, error) {
-			ids, err := opts.Client.TopStories(ctx)
+			count := countFor(ctx)
+			list := listFrom(ctx, defaultList)
+			ids, err := opts.Client.Stories(ctx, list)
 			if err != nil {
 				var zero WidgetViewModel
 				return zero, err
 			}
 
-			if len(ids) > count {
-				ids = ids[:count]
-			}
-
-			items := make([]*Item, len(ids))
-
-			// Concurrency limit so we don't open too many quesitons
-			sem := make(chan struct{}, 8)
-
-			var wg sync.WaitGroup
-			errCh := make(chan error, 1)
-
-			for i, id := range ids {
-				wg.Add(1)
-				go func(i int, id int64) {
-					defer wg.Done()
-
-					sem <- struct{}{}
-					defer func() { <-sem }()
-
-					it, e := opts.Client.Item(ctx, id)
-					if e != nil {
-						select {
-						case errCh <- e:
-						default:
-						}
-						return
-					}
-					items[i] = it
-				}(i, id)
-			}
-
-			wg.Wait()
-
-			select {
-			case e := <-errCh:
+			now := time.Now()
+			stories, missing, err := collect(ctx, items, ids, count, opts.Filter, now)
+			if err != nil {
 				var zero WidgetViewModel
-				return zero, e
-			default:
+				return zero, err
+			}
+			if missing > 0 && opts.Log != nil {
+				opts.Log.Warn("hn_items_unavailable",
+					slog.String("list", string(list)),
+					slog.Int("missing", missing))
 			}
 
-			now := time.Now()
-			return BuildViewModel(now, items), nil
+			vm := BuildViewModel(now, stories)
+			vm.List = list
+			vm.Degraded = missing > 0
+			highlight(vm.Entries, opts.Highlight)
+			return vm, nil
 		},
 
 		Render: func(vm WidgetViewModel) templ.Component {
-			return HackerNewsWidgetView(vm)
+			return HackerNewsWidgetView(vm, w.lists)
 		},
 
 		Error: func(_ error) templ.Component {
@@ -113,4 +166,72 @@ func NewWidgetHandler(opts Options) http.Handler {
 			return vm
 		},
 	}
+	return w
+}
+
+func (w *Widget) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.Handler.ServeHTTP(rw, w.selectList(r))
+}
+
+func (w *Widget) ServeJSON(rw http.ResponseWriter, r *http.Request) {
+	w.Handler.ServeJSON(rw, w.selectList(r))
+}
+
+// selectList puts the requested list into the context; unknown or disabled lists are
+// ignored in favor of the default.
+func (w *Widget) selectList(r *http.Request) *http.Request {
+	l := List(r.URL.Query().Get("list"))
+	if !l.Valid() || !slices.Contains(w.lists, l) {
+		return r
+	}
+	return r.WithContext(withList(r.Context(), l))
+}
+
+// maxScan bounds how many items one refresh may fetch while filling the count.
+const maxScan = 200
+
+// collect gets items in list order until count of them pass the filter. Without an
+// active filter that is the first count IDs; otherwise it fetches in batches sized to
+// the shortfall (twice it, as a guess at the pass rate). Items that fail to load, and
+// deleted or dead ones, are skipped and more fetched in their place, unless most of
+// them fail.
+func collect(ctx context.Context, c *itemCache, ids []int64, count int, f Filter, now time.Time) (items []*Item, missing int, err error) {
+	ids = ids[:min(len(ids), maxScan)]
+
+	var attempted int
+	for len(ids) > 0 && len(items) < count {
+		need := count - len(items)
+		batch := need
+		if f.Active() {
+			batch = max(need*2, 10)
+		}
+		batch = min(batch, len(ids))
+
+		res := c.get(ctx, ids[:batch])
+		ids = ids[batch:]
+
+		attempted += batch
+		missing += res.failed
+		if missing*2 > attempted {
+			return nil, missing, errors.Join(errTooManyFailed, res.err)
+		}
+
+		for _, it := range res.items {
+			if it != nil && !it.Deleted && !it.Dead && len(items) < count && f.keep(it, now) {
+				items = append(items, it)
+			}
+		}
+	}
+	return items, missing, nil
+}
+
+func clampCount(n int) int {
+	if n <= 0 {
+		return 10
+	}
+	if n > 50 {
+		// keep it sane
+		return 50
+	}
+	return n
 }
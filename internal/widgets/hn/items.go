@@ -0,0 +1,92 @@
+package hn
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/cache"
+)
+
+// itemCache keeps fetched items for ttl, shared by every list and variant, so a
+// refresh only refetches items that expired (scores and comment counts change, titles
+// rarely do). Expired items stay as a fallback for when their refetch fails.
+type itemCache struct {
+	client *Client
+	ttl    time.Duration
+	cache  cache.Keyed[*Item]
+}
+
+func newItemCache(c *Client, ttl time.Duration) *itemCache {
+	// Enough for every list's scan window.
+	ic := &itemCache{client: c, ttl: ttl}
+	ic.cache.MaxEntries = len(AllLists) * maxScan
+	return ic
+}
+
+// itemFetch is the result of fetching a batch of items, in the order of the IDs
+// asked for. Items that could not be fetched (and had nothing cached) are nil.
+type itemFetch struct {
+	items  []*Item
+	failed int
+	err    error // first failure, if any
+}
+
+// get returns ids' items, fetching those not fresh in the cache concurrently. A failed
+// fetch falls back to the expired copy when there is one.
+func (c *itemCache) get(ctx context.Context, ids []int64) itemFetch {
+	now := time.Now()
+	res := itemFetch{items: make([]*Item, len(ids))}
+
+	// Concurrency limit so we don't open too many connections
+	sem := make(chan struct{}, 8)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for i, id := range ids {
+		key := strconv.FormatInt(id, 10)
+		cached, _, state := c.cache.Get(key, now)
+		if state == cache.Fresh {
+			res.items[i] = cached
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, key string, id int64) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			it, err := c.client.Item(ctx, id)
+			if err == nil {
+				c.cache.Set(key, it, time.Now().Add(c.ttl))
+				res.items[i] = it
+				return
+			}
+			if cached != nil {
+				res.items[i] = cached
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.failed++
+			if res.err == nil {
+				res.err = err
+			}
+		}(i, key, id)
+	}
+
+	wg.Wait()
+	return res
+}
+
+// errTooManyFailed means most item fetches failed; the refresh fails rather than
+// showing a mostly empty list.
+var errTooManyFailed = errors.New("hn: most item fetches failed")
@@ -0,0 +1,61 @@
+package hn
+
+import "context"
+
+// List is one of the Hacker News story lists.
+type List string
+
+const (
+	ListTop  List = "top"
+	ListNew  List = "new"
+	ListBest List = "best"
+	ListAsk  List = "ask"
+	ListShow List = "show"
+	ListJobs List = "jobs"
+)
+
+// AllLists is every list, in the order HN's own navigation shows them.
+var AllLists = []List{ListTop, ListNew, ListBest, ListAsk, ListShow, ListJobs}
+
+var listEndpoints = map[List]string{
+	ListTop:  "topstories",
+	ListNew:  "newstories",
+	ListBest: "beststories",
+	ListAsk:  "askstories",
+	ListShow: "showstories",
+	ListJobs: "jobstories",
+}
+
+var listLabels = map[List]string{
+	ListTop:  "Top",
+	ListNew:  "New",
+	ListBest: "Best",
+	ListAsk:  "Ask",
+	ListShow: "Show",
+	ListJobs: "Jobs",
+}
+
+// Valid reports whether l is a known list.
+func (l List) Valid() bool {
+	_, ok := listEndpoints[l]
+	return ok
+}
+
+func (l List) Label() string {
+	return listLabels[l]
+}
+
+type listKey struct{}
+
+// withList selects the list to load for this request.
+func withList(ctx context.Context, l List) context.Context {
+	return context.WithValue(ctx, listKey{}, l)
+}
+
+// listFrom returns the list selected for ctx, or def.
+func listFrom(ctx context.Context, def List) List {
+	if l, ok := ctx.Value(listKey{}).(List); ok {
+		return l
+	}
+	return def
+}
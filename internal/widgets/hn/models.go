@@ -16,6 +16,8 @@ type Item struct {
 
 	Descendents int64 `json:"descendants"` // comment count (stories)
 
-	// Optional fields we might use later
-	Kids []int64 `json:"kids"` // comment IDs
+	Kids []int64 `json:"kids"` // comment IDs, in ranked display order
+
+	Deleted bool `json:"deleted"`
+	Dead    bool `json:"dead"`
 }
@@ -0,0 +1,45 @@
+package hn
+
+import (
+	"slices"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+// trendWindow is how long a story must have been absent to count as new.
+const trendWindow = 12 * time.Hour
+
+// withTrend marks stories not seen within trendWindow as new and sets RankDelta from
+// the previous recorded front page. Without history nothing is marked, so a fresh
+// install doesn't flag every story.
+func withTrend(v WidgetViewModel, past []widgetkit.Point[WidgetViewModel]) WidgetViewModel {
+	if len(past) == 0 {
+		return v
+	}
+
+	seen := make(map[int64]bool)
+	for _, p := range past {
+		for _, e := range p.Value.Entries {
+			seen[e.ID] = true
+		}
+	}
+	prevRank := make(map[int64]int)
+	for _, e := range past[len(past)-1].Value.Entries {
+		prevRank[e.ID] = e.Rank
+	}
+
+	entries := slices.Clone(v.Entries)
+	for i := range entries {
+		e := &entries[i]
+		if !seen[e.ID] {
+			e.IsNew = true
+			continue
+		}
+		if r, ok := prevRank[e.ID]; ok {
+			e.RankDelta = r - e.Rank
+		}
+	}
+	v.Entries = entries
+	return v
+}
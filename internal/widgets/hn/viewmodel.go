@@ -8,23 +8,38 @@ import (
 )
 
 type Entry struct {
-	Rank     int
-	Title    string
-	URL      string
-	Domain   string
-	Score    int
-	By       string
-	Age      string
-	Comments int
+	ID       int64  `json:"id"`
+	Rank     int    `json:"rank"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Domain   string `json:"domain,omitempty"`
+	Score    int    `json:"score"`
+	By       string `json:"by"`
+	Age      string `json:"age"`
+	Comments int    `json:"comments"`
+	Job      bool   `json:"job,omitempty"` // job ads have no score or comments
+
+	// Watched lists the highlight keywords found in the title.
+	Watched []string `json:"watched,omitempty"`
+
+	// Trends (see withTrend): IsNew for stories new to the front page, RankDelta > 0
+	// for stories that moved up since the previous refresh.
+	IsNew     bool `json:"is_new,omitempty"`
+	RankDelta int  `json:"rank_delta,omitempty"`
 }
 
 type WidgetViewModel struct {
-	UpdatedAt string
-	Entries   []Entry
+	List      List    `json:"list"`
+	UpdatedAt string  `json:"updated_at"`
+	Entries   []Entry `json:"entries"`
+
+	// Degraded is set when some stories couldn't be loaded and were left out (or
+	// replaced by the next ones on the list).
+	Degraded bool `json:"degraded,omitempty"`
 
 	// stale indicator (set by widget.MarkStale)
-	IsStale bool
-	StaleBy string
+	IsStale bool   `json:"is_stale"`
+	StaleBy string `json:"stale_by,omitempty"`
 }
 
 // BuildViewModel converts items (in rank order) into a render-friendly view model.
@@ -38,6 +53,7 @@ func BuildViewModel(now time.Time, items []*Item) WidgetViewModel {
 
 		link := itemURL(it)
 		entries = append(entries, Entry{
+			ID:       it.ID,
 			Rank:     i + 1,
 			Title:    it.Title,
 			URL:      link,
@@ -46,6 +62,7 @@ func BuildViewModel(now time.Time, items []*Item) WidgetViewModel {
 			By:       it.By,
 			Age:      relativeAge(now, it.Time),
 			Comments: int(it.Descendents),
+			Job:      it.Type == "job",
 		})
 	}
 
@@ -55,12 +72,23 @@ func BuildViewModel(now time.Time, items []*Item) WidgetViewModel {
 	}
 }
 
+// highlight sets Watched on entries whose titles mention any of keywords.
+func highlight(entries []Entry, keywords []string) {
+	for i := range entries {
+		entries[i].Watched = matchWords(entries[i].Title, keywords)
+	}
+}
+
 // itemURL links to the external URL when present; otherwise links to the HN item page (Ask HN, etc.).
 func itemURL(it *Item) string {
 	if it.URL != "" {
 		return it.URL
 	}
-	return "https://news.ycombinator.com/item?id=" + strconv.FormatInt(it.ID, 10)
+	return discussionURL(it.ID)
+}
+
+func discussionURL(id int64) string {
+	return "https://news.ycombinator.com/item?id=" + strconv.FormatInt(id, 10)
 }
 
 func domainFromURL(raw string) string {
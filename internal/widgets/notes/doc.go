@@ -0,0 +1,5 @@
+// Package notes implements shared markdown notes. Markdown is rendered and sanitized on
+// the server (internal/markdown), so the fragments need no client-side script and stay
+// within the strict CSP. Each note is edited in place: the edit form, save and delete
+// swap only that note's <article>.
+package notes
@@ -0,0 +1,31 @@
+package notes
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxBodyLen = 20000
+
+// Form is the create/edit form state (ID is zero when creating).
+type Form struct {
+	ID    int64
+	Body  string
+	Error string
+}
+
+func formFromRequest(r *http.Request) Form {
+	return Form{Body: strings.TrimSpace(r.PostFormValue("body"))}
+}
+
+// validate returns a user-facing message, or "" when the form is valid.
+func (f Form) validate() string {
+	switch {
+	case f.Body == "":
+		return "Write something first."
+	case utf8.RuneCountInString(f.Body) > maxBodyLen:
+		return "Notes must be at most 20000 characters."
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+package notes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/internal/ui/components"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+type Options struct {
+	Queries *store.Queries
+	Log     *slog.Logger
+}
+
+var errNoStore = errors.New("notes: no database configured")
+
+// Widget serves the notes fragment (via the embedded widgetkit.Handler) and the
+// per-note routes.
+type Widget struct {
+	widgetkit.Handler[WidgetViewModel]
+
+	q   *store.Queries
+	log *slog.Logger
+}
+
+func NewWidgetHandler(opts Options) *Widget {
+	log := opts.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	w := &Widget{q: opts.Queries, log: log}
+	w.Handler = widgetkit.Handler[WidgetViewModel]{
+		Name: "notes",
+		Log:  opts.Log,
+
+		// No cache: notes are shared, so writes must show up immediately everywhere.
+		Fetch: w.load,
+
+		Render: func(vm WidgetViewModel) templ.Component {
+			return WidgetView(vm, nil)
+		},
+
+		Error: func(_ error) templ.Component {
+			return components.WidgetError("Notes", "/widgets/notes")
+		},
+	}
+	return w
+}
+
+func (w *Widget) Mount(r chi.Router) {
+	r.Get("/new", w.newForm)
+	r.Post("/", w.create)
+	r.Get("/{id}", w.show)
+	r.Get("/{id}/edit", w.editForm)
+	r.Put("/{id}", w.update)
+	r.Delete("/{id}", w.remove)
+}
+
+func (w *Widget) load(ctx context.Context) (WidgetViewModel, error) {
+	if w.q == nil {
+		return WidgetViewModel{}, errNoStore
+	}
+	rows, err := w.q.ListNotes(ctx)
+	if err != nil {
+		return WidgetViewModel{}, err
+	}
+	return BuildViewModel(rows), nil
+}
+
+func (w *Widget) newForm(rw http.ResponseWriter, r *http.Request) {
+	w.renderWidget(rw, r, &Form{})
+}
+
+// create adds a note and answers with the whole widget (new note on top, form closed).
+func (w *Widget) create(rw http.ResponseWriter, r *http.Request) {
+	f := formFromRequest(r)
+	if msg := f.validate(); msg != "" {
+		f.Error = msg
+		w.renderWidget(rw, r, &f)
+		return
+	}
+
+	if err := w.q.CreateNote(r.Context(), f.Body); err != nil {
+		w.fail(rw, r, "note_create_failed", err)
+		return
+	}
+	w.Invalidate(r.Context())
+	w.renderWidget(rw, r, nil)
+}
+
+// show answers with one rendered note (used by the edit form's Cancel).
+func (w *Widget) show(rw http.ResponseWriter, r *http.Request) {
+	n, ok := w.note(rw, r)
+	if !ok {
+		return
+	}
+	w.render(rw, r, noteView(buildNote(n)))
+}
+
+func (w *Widget) editForm(rw http.ResponseWriter, r *http.Request) {
+	n, ok := w.note(rw, r)
+	if !ok {
+		return
+	}
+	w.render(rw, r, noteForm(Form{ID: n.ID, Body: n.Body}))
+}
+
+// update saves a note and answers with just that note.
+func (w *Widget) update(rw http.ResponseWriter, r *http.Request) {
+	id, ok := noteID(rw, r)
+	if !ok {
+		return
+	}
+
+	f := formFromRequest(r)
+	f.ID = id
+	if msg := f.validate(); msg != "" {
+		f.Error = msg
+		w.render(rw, r, noteForm(f))
+		return
+	}
+
+	n, err := w.q.UpdateNote(r.Context(), store.UpdateNoteParams{Body: f.Body, ID: id})
+	if err != nil {
+		w.fail(rw, r, "note_update_failed", err)
+		return
+	}
+	if n == 0 {
+		http.NotFound(rw, r)
+		return
+	}
+	w.Invalidate(r.Context())
+	w.show(rw, r)
+}
+
+// remove deletes a note and answers with an empty body, which removes its <article>.
+func (w *Widget) remove(rw http.ResponseWriter, r *http.Request) {
+	id, ok := noteID(rw, r)
+	if !ok {
+		return
+	}
+
+	n, err := w.q.DeleteNote(r.Context(), id)
+	if err != nil {
+		w.fail(rw, r, "note_delete_failed", err)
+		return
+	}
+	if n == 0 {
+		http.NotFound(rw, r)
+		return
+	}
+	w.Invalidate(r.Context())
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusOK)
+}
+
+// note loads the note named in the URL, answering 404 when it doesn't exist.
+func (w *Widget) note(rw http.ResponseWriter, r *http.Request) (store.Note, bool) {
+	id, ok := noteID(rw, r)
+	if !ok {
+		return store.Note{}, false
+	}
+
+	n, err := w.q.GetNote(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(rw, r)
+		return store.Note{}, false
+	}
+	if err != nil {
+		w.fail(rw, r, "note_load_failed", err)
+		return store.Note{}, false
+	}
+	return n, true
+}
+
+// renderWidget shows the whole widget, with the new-note form open when form is set.
+// Validation errors are sent with 200 because HTMX does not swap 4xx responses.
+func (w *Widget) renderWidget(rw http.ResponseWriter, r *http.Request, form *Form) {
+	vm, err := w.load(r.Context())
+	if err != nil {
+		w.fail(rw, r, "notes_load_failed", err)
+		return
+	}
+	w.render(rw, r, WidgetView(vm, form))
+}
+
+func (w *Widget) render(rw http.ResponseWriter, r *http.Request, c templ.Component) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	if err := c.Render(r.Context(), rw); err != nil {
+		w.log.ErrorContext(r.Context(), "notes_render_failed", slog.Any("err", err))
+	}
+}
+
+func (w *Widget) fail(rw http.ResponseWriter, r *http.Request, msg string, err error) {
+	w.log.ErrorContext(r.Context(), msg, slog.Any("err", err))
+	http.Error(rw, "something went wrong", http.StatusInternalServerError)
+}
+
+func noteID(rw http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.NotFound(rw, r)
+		return 0, false
+	}
+	return id, true
+}
+
+func notePath(id int64) string {
+	return "/widgets/notes/" + strconv.FormatInt(id, 10)
+}
+
+func noteDOMID(id int64) string {
+	return "note-" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,41 @@
+package notes
+
+import (
+	"html"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/markdown"
+	"github.com/patrickneise/dashboard/internal/store"
+)
+
+type Note struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	HTML      string    `json:"html"` // sanitized rendering of Body
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WidgetViewModel struct {
+	Notes []Note `json:"notes"`
+}
+
+func BuildViewModel(rows []store.Note) WidgetViewModel {
+	vm := WidgetViewModel{Notes: make([]Note, 0, len(rows))}
+	for _, n := range rows {
+		vm.Notes = append(vm.Notes, buildNote(n))
+	}
+	return vm
+}
+
+func buildNote(n store.Note) Note {
+	out, err := markdown.Render(n.Body)
+	if err != nil {
+		// Not expected for in-memory input; show the source rather than nothing.
+		out = "<pre>" + html.EscapeString(n.Body) + "</pre>"
+	}
+	return Note{ID: n.ID, Body: n.Body, HTML: out, UpdatedAt: n.UpdatedAt}
+}
+
+func updatedLabel(t time.Time) string {
+	return t.Local().Format("Jan 2, 3:04 PM")
+}
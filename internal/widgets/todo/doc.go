@@ -0,0 +1,4 @@
+// Package todo implements a shared (team-wide) todo list with inline create, toggle,
+// reorder and delete. Responses are partial: toggling swaps one item, reorder and
+// delete swap the list, and the open-items counter updates out of band.
+package todo
@@ -0,0 +1,231 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/store"
+	"github.com/patrickneise/dashboard/internal/ui/components"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+const maxTextLen = 200
+
+type Options struct {
+	Store *store.Store
+	Log   *slog.Logger
+}
+
+var errNoStore = errors.New("todo: no database configured")
+
+// Widget serves the todo fragment (via the embedded widgetkit.Handler) and the inline
+// editing routes.
+type Widget struct {
+	widgetkit.Handler[WidgetViewModel]
+
+	st  *store.Store
+	log *slog.Logger
+}
+
+func NewWidgetHandler(opts Options) *Widget {
+	log := opts.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	w := &Widget{st: opts.Store, log: log}
+	w.Handler = widgetkit.Handler[WidgetViewModel]{
+		Name: "todo",
+		Log:  opts.Log,
+
+		// No cache: the list is shared, so every serve process must see writes at once.
+		Fetch: w.load,
+
+		Render: func(vm WidgetViewModel) templ.Component {
+			return WidgetView(vm)
+		},
+
+		Error: func(_ error) templ.Component {
+			return components.WidgetError("Todo", "/widgets/todo")
+		},
+	}
+	return w
+}
+
+func (w *Widget) Mount(r chi.Router) {
+	r.Post("/", w.create)
+	r.Post("/{id}/toggle", w.toggle)
+	r.Post("/{id}/up", w.move(-1))
+	r.Post("/{id}/down", w.move(+1))
+	r.Delete("/{id}", w.remove)
+}
+
+func (w *Widget) load(ctx context.Context) (WidgetViewModel, error) {
+	if w.st == nil {
+		return WidgetViewModel{}, errNoStore
+	}
+	rows, err := w.st.ListTodos(ctx)
+	if err != nil {
+		return WidgetViewModel{}, err
+	}
+	return BuildViewModel(rows), nil
+}
+
+// create adds an item and answers with the whole widget, which also resets the form.
+func (w *Widget) create(rw http.ResponseWriter, r *http.Request) {
+	text := strings.TrimSpace(r.PostFormValue("text"))
+
+	var msg string
+	switch {
+	case text == "":
+		msg = "Enter a task."
+	case utf8.RuneCountInString(text) > maxTextLen:
+		msg = "Tasks must be at most 200 characters."
+	}
+	if msg != "" {
+		w.render(rw, r, func(vm WidgetViewModel) templ.Component {
+			vm.Error = msg
+			return WidgetView(vm)
+		})
+		return
+	}
+
+	if err := w.st.CreateTodo(r.Context(), text); err != nil {
+		w.fail(rw, r, "todo_create_failed", err)
+		return
+	}
+	w.Invalidate(r.Context())
+	w.render(rw, r, WidgetView)
+}
+
+// toggle flips one item and answers with just that item.
+func (w *Widget) toggle(rw http.ResponseWriter, r *http.Request) {
+	id, ok := todoID(rw, r)
+	if !ok {
+		return
+	}
+
+	n, err := w.st.ToggleTodo(r.Context(), id)
+	if err != nil {
+		w.fail(rw, r, "todo_toggle_failed", err)
+		return
+	}
+	if n == 0 {
+		http.NotFound(rw, r)
+		return
+	}
+	w.Invalidate(r.Context())
+	w.render(rw, r, func(vm WidgetViewModel) templ.Component {
+		return itemResponse(vm, id)
+	})
+}
+
+// move swaps an item with its neighbour (dir -1 up, +1 down) and answers with the list.
+func (w *Widget) move(dir int) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		id, ok := todoID(rw, r)
+		if !ok {
+			return
+		}
+
+		err := w.st.InTx(r.Context(), func(q *store.Queries) error {
+			t, err := q.GetTodo(r.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			var other store.Todo
+			if dir < 0 {
+				other, err = q.GetTodoBefore(r.Context(), t.Position)
+			} else {
+				other, err = q.GetTodoAfter(r.Context(), t.Position)
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil // already first/last
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := q.SetTodoPosition(r.Context(), store.SetTodoPositionParams{Position: other.Position, ID: t.ID}); err != nil {
+				return err
+			}
+			return q.SetTodoPosition(r.Context(), store.SetTodoPositionParams{Position: t.Position, ID: other.ID})
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(rw, r)
+			return
+		}
+		if err != nil {
+			w.fail(rw, r, "todo_move_failed", err)
+			return
+		}
+		w.Invalidate(r.Context())
+		w.render(rw, r, listResponse)
+	}
+}
+
+// remove deletes an item and answers with the list.
+func (w *Widget) remove(rw http.ResponseWriter, r *http.Request) {
+	id, ok := todoID(rw, r)
+	if !ok {
+		return
+	}
+
+	n, err := w.st.DeleteTodo(r.Context(), id)
+	if err != nil {
+		w.fail(rw, r, "todo_delete_failed", err)
+		return
+	}
+	if n == 0 {
+		http.NotFound(rw, r)
+		return
+	}
+	w.Invalidate(r.Context())
+	w.render(rw, r, listResponse)
+}
+
+// render loads the current list and writes the partial built by view.
+func (w *Widget) render(rw http.ResponseWriter, r *http.Request, view func(WidgetViewModel) templ.Component) {
+	vm, err := w.load(r.Context())
+	if err != nil {
+		w.fail(rw, r, "todo_load_failed", err)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	if err := view(vm).Render(r.Context(), rw); err != nil {
+		w.log.ErrorContext(r.Context(), "todo_render_failed", slog.Any("err", err))
+	}
+}
+
+func (w *Widget) fail(rw http.ResponseWriter, r *http.Request, msg string, err error) {
+	w.log.ErrorContext(r.Context(), msg, slog.Any("err", err))
+	http.Error(rw, "something went wrong", http.StatusInternalServerError)
+}
+
+func todoID(rw http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.NotFound(rw, r)
+		return 0, false
+	}
+	return id, true
+}
+
+func itemPath(id int64, action string) string {
+	p := "/widgets/todo/" + strconv.FormatInt(id, 10)
+	if action != "" {
+		p += "/" + action
+	}
+	return p
+}
@@ -0,0 +1,28 @@
+package todo
+
+import "github.com/patrickneise/dashboard/internal/store"
+
+type Item struct {
+	ID   int64  `json:"id"`
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+type WidgetViewModel struct {
+	Items     []Item `json:"items"`
+	Remaining int    `json:"remaining"`
+
+	// Error is a validation message for the add form (not part of the JSON data).
+	Error string `json:"-"`
+}
+
+func BuildViewModel(rows []store.Todo) WidgetViewModel {
+	vm := WidgetViewModel{Items: make([]Item, 0, len(rows))}
+	for _, t := range rows {
+		vm.Items = append(vm.Items, Item{ID: t.ID, Text: t.Text, Done: t.Done})
+		if !t.Done {
+			vm.Remaining++
+		}
+	}
+	return vm
+}
@@ -0,0 +1,125 @@
+package weather
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+
+	"github.com/patrickneise/dashboard/internal/cache"
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+// AlertsProvider returns the active severe weather alerts for a point. Providers that
+// don't cover the point return no alerts rather than an error.
+type AlertsProvider interface {
+	Alerts(ctx context.Context, lat, lon float64) ([]Alert, error)
+}
+
+// Severity follows the CAP levels used by most alerting services.
+type Severity string
+
+const (
+	SeverityExtreme  Severity = "Extreme"
+	SeveritySevere   Severity = "Severe"
+	SeverityModerate Severity = "Moderate"
+	SeverityMinor    Severity = "Minor"
+	SeverityUnknown  Severity = "Unknown"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityExtreme:
+		return 0
+	case SeveritySevere:
+		return 1
+	case SeverityModerate:
+		return 2
+	case SeverityMinor:
+		return 3
+	}
+	return 4
+}
+
+type Alert struct {
+	Event    string    `json:"event"`    // e.g. "Flood Warning"
+	Headline string    `json:"headline"` // one line summary from the issuer
+	Severity Severity  `json:"severity"`
+	Expires  time.Time `json:"expires"` // in the issuer's local offset
+}
+
+// ExpiresLabel is e.g. "until Tue 18:00".
+func (a Alert) ExpiresLabel() string {
+	if a.Expires.IsZero() {
+		return ""
+	}
+	return "until " + a.Expires.Format("Mon 15:04")
+}
+
+type AlertsViewModel struct {
+	Alerts []Alert `json:"alerts"` // most severe first
+}
+
+// defaultAlertsTTL is short: alerts are time critical, unlike the forecast.
+const defaultAlertsTTL = 2 * time.Minute
+
+// newAlertsHandler builds the banner as its own widgetkit.Handler, so alerts get their
+// own cache and TTL and refresh without refetching the forecast. Failures render
+// nothing (the banner stays as it was) instead of a widget error.
+func newAlertsHandler(p AlertsProvider, ttl time.Duration, effective func(context.Context) settings, opts Options) widgetkit.Handler[AlertsViewModel] {
+	return widgetkit.Handler[AlertsViewModel]{
+		Name:  "weather-alerts",
+		TTL:   ttl,
+		Cache: &cache.Keyed[AlertsViewModel]{},
+		Log:   opts.Log,
+
+		Variant: func(ctx context.Context) string {
+			return effective(ctx).pointKey()
+		},
+
+		Fetch: func(ctx context.Context) (AlertsViewModel, error) {
+			s := effective(ctx)
+			alerts, err := p.Alerts(ctx, s.Lat, s.Lon)
+			if err != nil {
+				return AlertsViewModel{}, err
+			}
+			now := time.Now()
+			alerts = slices.DeleteFunc(alerts, func(a Alert) bool {
+				return !a.Expires.IsZero() && a.Expires.Before(now)
+			})
+			slices.SortStableFunc(alerts, func(a, b Alert) int {
+				return cmp.Or(
+					cmp.Compare(a.Severity.rank(), b.Severity.rank()),
+					a.Expires.Compare(b.Expires),
+				)
+			})
+			return AlertsViewModel{Alerts: alerts}, nil
+		},
+
+		Render: func(vm AlertsViewModel) templ.Component {
+			return alertsBanner(vm)
+		},
+
+		Error: func(_ error) templ.Component {
+			return templ.NopComponent
+		},
+	}
+}
+
+func severityClass(s Severity) string {
+	switch s {
+	case SeverityExtreme, SeveritySevere:
+		return "bg-red-50 border-red-300 text-red-900"
+	case SeverityModerate:
+		return "bg-orange-50 border-orange-300 text-orange-900"
+	}
+	return "bg-yellow-50 border-yellow-200 text-yellow-900"
+}
+
+// pollInterval formats d for hx-trigger's "every", e.g. "120s".
+func pollInterval(d time.Duration) string {
+	return strconv.Itoa(int(d/time.Second)) + "s"
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/patrickneise/dashboard/internal/httpx"
+	"github.com/patrickneise/dashboard/internal/prefs"
 )
 
 const openMeteoBaseURL = "https://api.open-meteo.com/v1/forecast"
@@ -20,13 +21,30 @@ func NewClient(h *httpx.Client) *Client {
 	return &Client{http: h}
 }
 
-func (c *Client) FetchCurrentAndHourly(ctx context.Context, lat, lon float64, hours int) (*OpenMeteoResponse, error) {
+const (
+	currentVars = "temperature_2m,apparent_temperature,wind_speed_10m,precipitation,relative_humidity_2m,weather_code,uv_index,is_day"
+	hourlyVars  = "temperature_2m,weather_code,precipitation_probability,precipitation,is_day"
+	dailyVars   = "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum,precipitation_probability_max,uv_index_max,sunrise,sunset"
+)
+
+// FetchForecast requests current conditions, the next hours and the next days, with
+// every value in the requested unit system.
+func (c *Client) FetchForecast(ctx context.Context, lat, lon float64, hours, days int, units prefs.Units) (*OpenMeteoResponse, error) {
+	u := unitsFor(units)
+
 	url := fmt.Sprintf(
-		"%s?latitude=%.4f&longitude=%.4f&hourly=temperature_2m&current=temperature_2m,apparent_temperature,wind_speed_10m&timezone=auto&forecast_hours=%d&temperature_unit=fahrenheit",
+		"%s?latitude=%.4f&longitude=%.4f&current=%s&hourly=%s&daily=%s&timezone=auto&forecast_hours=%d&forecast_days=%d&temperature_unit=%s&wind_speed_unit=%s&precipitation_unit=%s",
 		openMeteoBaseURL,
 		lat,
 		lon,
+		currentVars,
+		hourlyVars,
+		dailyVars,
 		hours,
+		days,
+		u.temperature,
+		u.windSpeed,
+		u.precipitation,
 	)
 
 	var data OpenMeteoResponse
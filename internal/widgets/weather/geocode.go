@@ -0,0 +1,141 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Open-Meteo has no reverse geocoding; OpenStreetMap's Nominatim does (at most one
+// request per second, identified by User-Agent, which the name cache keeps us under).
+const nominatimReverseURL = "https://nominatim.openstreetmap.org/reverse"
+
+type nominatimResponse struct {
+	Address struct {
+		City         string `json:"city"`
+		Town         string `json:"town"`
+		Village      string `json:"village"`
+		Hamlet       string `json:"hamlet"`
+		Municipality string `json:"municipality"`
+		County       string `json:"county"`
+		State        string `json:"state"`
+		StateCode    string `json:"ISO3166-2-lvl4"` // e.g. "US-MD"
+		Country      string `json:"country"`
+		CountryCode  string `json:"country_code"`
+	} `json:"address"`
+}
+
+// ReverseGeocode returns a short place name for the coordinates, e.g. "Annapolis, MD"
+// or "Lyon, Auvergne-Rhône-Alpes". lang is an Accept-Language value for the names.
+func (c *Client) ReverseGeocode(ctx context.Context, lat, lon float64, lang string) (string, error) {
+	q := url.Values{}
+	q.Set("format", "jsonv2")
+	q.Set("lat", fmt.Sprintf("%.4f", lat))
+	q.Set("lon", fmt.Sprintf("%.4f", lon))
+	q.Set("zoom", "10") // city level
+	if lang != "" {
+		q.Set("accept-language", lang)
+	}
+
+	var data nominatimResponse
+	if err := c.http.GetJSON(ctx, nominatimReverseURL+"?"+q.Encode(), &data); err != nil {
+		return "", err
+	}
+
+	name := placeName(data)
+	if name == "" {
+		return "", fmt.Errorf("reverse geocode %.4f,%.4f: no place name", lat, lon)
+	}
+	return name, nil
+}
+
+func placeName(r nominatimResponse) string {
+	a := r.Address
+
+	locality := firstNonEmpty(a.City, a.Town, a.Village, a.Hamlet, a.Municipality, a.County)
+
+	// US states read best as their postal code ("MD"); elsewhere use the region name.
+	region := a.State
+	if code, ok := strings.CutPrefix(a.StateCode, "US-"); ok && a.CountryCode == "us" {
+		region = code
+	}
+	if region == "" {
+		region = a.Country
+	}
+
+	switch {
+	case locality != "" && region != "":
+		return locality + ", " + region
+	case locality != "":
+		return locality
+	default:
+		return region
+	}
+}
+
+func firstNonEmpty(vs ...string) string {
+	for _, v := range vs {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// coordsLabel is the fallback name when no place name is known, e.g. "38.95, -76.48".
+func coordsLabel(lat, lon float64) string {
+	return fmt.Sprintf("%.2f, %.2f", lat, lon)
+}
+
+const openMeteoGeocodingURL = "https://geocoding-api.open-meteo.com/v1/search"
+
+// Place is one location search result.
+type Place struct {
+	Name    string  `json:"name"`
+	Region  string  `json:"admin1"` // state/province
+	Country string  `json:"country"`
+	Lat     float64 `json:"latitude"`
+	Lon     float64 `json:"longitude"`
+}
+
+// Label is the full description shown in search results, e.g. "Annapolis, Maryland,
+// United States".
+func (p Place) Label() string {
+	parts := []string{p.Name}
+	for _, s := range []string{p.Region, p.Country} {
+		if s != "" && s != p.Name {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ShortName is the name saved with a picked location, e.g. "Annapolis, Maryland".
+func (p Place) ShortName() string {
+	if region := firstNonEmpty(p.Region, p.Country); region != "" && region != p.Name {
+		return p.Name + ", " + region
+	}
+	return p.Name
+}
+
+// SearchPlaces looks up places by name with Open-Meteo's geocoding API. lang is the
+// language for the returned names.
+func (c *Client) SearchPlaces(ctx context.Context, name, lang string, count int) ([]Place, error) {
+	q := url.Values{}
+	q.Set("name", name)
+	q.Set("count", strconv.Itoa(count))
+	q.Set("format", "json")
+	if lang != "" {
+		q.Set("language", lang)
+	}
+
+	var data struct {
+		Results []Place `json:"results"` // absent when nothing matches
+	}
+	if err := c.http.GetJSON(ctx, openMeteoGeocodingURL+"?"+q.Encode(), &data); err != nil {
+		return nil, err
+	}
+	return data.Results, nil
+}
@@ -2,59 +2,164 @@ package weather
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"net/http"
 	"time"
 
 	"github.com/a-h/templ"
 	"github.com/patrickneise/dashboard/internal/cache"
+	"github.com/patrickneise/dashboard/internal/prefs"
 	"github.com/patrickneise/dashboard/internal/ui/components"
 	"github.com/patrickneise/dashboard/internal/widgetkit"
 )
 
 type Options struct {
-	Lat          float64
-	Lon          float64
-	Hours        int
+	Lat   float64
+	Lon   float64
+	Hours int // hourly forecast length (1-72, default 6)
+	Days  int // daily forecast length (1-16, default 5)
+	TTL   time.Duration
+
+	// LocationName labels the configured coordinates; empty means reverse geocoding
+	// them. Language is the Accept-Language for geocoded names (e.g. "en", "de").
 	LocationName string
-	TTL          time.Duration
+	Language     string
+
+	// Units is the default measurement system for temperature, wind and precipitation.
+	Units prefs.Units
+
+	// Snapshots, when set, serves values written by the worker; History records values
+	// for trends, kept for Retention (see widgetkit.Handler).
+	Snapshots widgetkit.SnapshotStore
+	History   widgetkit.HistoryStore
+	Retention time.Duration
+
+	// Provider supplies forecasts (use Failover to chain several); nil means Open-Meteo
+	// through Client. Client is also used for location search and names.
+	Provider Provider
+
+	// Prefs saves locations picked with the search box; nil disables the picker.
+	Prefs *prefs.Service
+
+	// Alerts, when set, feeds the severe weather banner, refreshed every AlertsTTL
+	// (default 2m) independently of the forecast.
+	Alerts    AlertsProvider
+	AlertsTTL time.Duration
 
 	Client *Client
 	Log    *slog.Logger
 }
 
-func NewWidgetHandler(opts Options) http.Handler {
+// Widget serves the weather fragment (via the embedded widgetkit.Handler), the alerts
+// banner and the location picker routes.
+type Widget struct {
+	widgetkit.Handler[WidgetViewModel]
+
+	alerts    *widgetkit.Handler[AlertsViewModel] // nil without a provider
+	alertsTTL time.Duration
+
+	client *Client
+	prefs  *prefs.Service
+	lang   string
+	log    *slog.Logger
+}
+
+func NewWidgetHandler(opts Options) *Widget {
 	c := opts.Client
 
+	log := opts.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
 	ttl := opts.TTL
 	if ttl <= 0 {
 		ttl = 5 * time.Minute
 	}
 
-	location := opts.LocationName
-	if location == "" {
-		location = "Weather"
+	hours := opts.Hours
+	if hours <= 0 {
+		hours = 6
+	}
+
+	days := opts.Days
+	if days <= 0 {
+		days = 5
+	}
+
+	units := opts.Units
+	if units == prefs.UnitsDefault {
+		units = prefs.UnitsImperial
 	}
 
-	return widgetkit.Handler[WidgetViewModel]{
-		Name:  "weather",
-		TTL:   ttl,
-		Cache: &cache.TTL[WidgetViewModel]{},
-		Log:   opts.Log,
+	// effective merges the signed-in user's preferences over the configured defaults.
+	effective := func(ctx context.Context) settings {
+		s := settings{Lat: opts.Lat, Lon: opts.Lon, Location: opts.LocationName, Units: units}
+		p := prefs.FromContext(ctx)
+		if p.HasLocation() {
+			s.Lat, s.Lon = *p.Lat, *p.Lon
+			s.Location = "" // the configured name is for the configured coordinates
+		}
+		if p.LocationName != "" {
+			s.Location = p.LocationName
+		}
+		if p.Units != prefs.UnitsDefault {
+			s.Units = p.Units
+		}
+		return s
+	}
+
+	provider := opts.Provider
+	if provider == nil {
+		provider = OpenMeteo{Client: c}
+	}
+
+	names := &placeNames{client: c, lang: opts.Language, log: opts.Log}
+
+	w := &Widget{client: c, prefs: opts.Prefs, lang: opts.Language, log: log}
+
+	if opts.Alerts != nil {
+		w.alertsTTL = opts.AlertsTTL
+		if w.alertsTTL <= 0 {
+			w.alertsTTL = defaultAlertsTTL
+		}
+		alerts := newAlertsHandler(opts.Alerts, w.alertsTTL, effective, opts)
+		w.alerts = &alerts
+	}
+
+	w.Handler = widgetkit.Handler[WidgetViewModel]{
+		Name:      "weather",
+		TTL:       ttl,
+		Cache:     &cache.Keyed[WidgetViewModel]{},
+		Snapshots: opts.Snapshots,
+		Log:       opts.Log,
+
+		History:     opts.History,
+		Retention:   opts.Retention,
+		TrendWindow: trendWindow,
+		Trend:       withTrend,
+
+		Variant: func(ctx context.Context) string {
+			return effective(ctx).key()
+		},
 
 		Fetch: func(ctx context.Context) (WidgetViewModel, error) {
-			resp, err := c.FetchCurrentAndHourly(ctx, opts.Lat, opts.Lon, opts.Hours)
+			s := effective(ctx)
+			f, err := provider.Forecast(ctx, Query{Lat: s.Lat, Lon: s.Lon, Hours: hours, Days: days, Units: s.Units})
 			if err != nil {
 				var zero WidgetViewModel
 				return zero, err
 			}
-			vm := toViewModel(resp)
-			vm.LocationName = location
+			vm := toViewModel(f, unitsFor(s.Units))
+			vm.LocationName = s.Location
+			if vm.LocationName == "" {
+				vm.LocationName = names.lookup(ctx, s.Lat, s.Lon)
+			}
 			return vm, nil
 		},
 
 		Render: func(vm WidgetViewModel) templ.Component {
-			return WeatherWidgetView(vm)
+			return WeatherWidgetView(vm, w.viewOptions())
 		},
 
 		Error: func(_ error) templ.Component {
@@ -67,40 +172,149 @@ func NewWidgetHandler(opts Options) http.Handler {
 			return vm
 		},
 	}
+	return w
 }
 
-func toViewModel(api *OpenMeteoResponse) WidgetViewModel {
-	updatedAt := api.Current.Time
-	// Try to parse the ISO8601 time; if it fails, just use the string
-	if t, err := time.Parse(time.RFC3339, api.Current.Time); err == nil {
-		updatedAt = t.Format("15:04") // local time HH:MM
+// settings are the effective weather options for one request.
+type settings struct {
+	Lat, Lon float64
+	Location string
+	Units    prefs.Units
+}
+
+func (s settings) key() string {
+	return fmt.Sprintf("%.4f,%.4f,%s,%s", s.Lat, s.Lon, s.Units, s.Location)
+}
+
+// pointKey identifies just the location (alerts don't depend on units or names).
+func (s settings) pointKey() string {
+	return fmt.Sprintf("%.4f,%.4f", s.Lat, s.Lon)
+}
+
+// ViewOptions are the widget features the fragment links to.
+type ViewOptions struct {
+	Picker      bool          // location search box
+	AlertsEvery time.Duration // banner refresh interval; zero without alerts
+}
+
+func (w *Widget) viewOptions() ViewOptions {
+	return ViewOptions{Picker: w.prefs != nil, AlertsEvery: w.alertsTTL}
+}
+
+// placeNames caches reverse-geocoded names per coordinate pair. Lookups never fail:
+// without a name the coordinates themselves are the label.
+type placeNames struct {
+	client *Client
+	lang   string
+	log    *slog.Logger
+	cache  cache.Keyed[string]
+}
+
+const (
+	placeNameTTL      = 7 * 24 * time.Hour
+	placeNameRetryTTL = 10 * time.Minute
+)
+
+func (n *placeNames) lookup(ctx context.Context, lat, lon float64) string {
+	now := time.Now()
+	key := fmt.Sprintf("%.4f,%.4f", lat, lon)
+
+	cached, _, state := n.cache.Get(key, now)
+	if state == cache.Fresh {
+		return cached
+	}
+
+	name, err := n.client.ReverseGeocode(ctx, lat, lon, n.lang)
+	if err != nil {
+		if n.log != nil {
+			n.log.Warn("weather_reverse_geocode_failed", slog.String("coords", key), slog.Any("err", err))
+		}
+		name = cached
+		if state == cache.Miss {
+			name = coordsLabel(lat, lon)
+		}
+		n.cache.Set(key, name, now.Add(placeNameRetryTTL))
+		return name
+	}
+
+	n.cache.Set(key, name, now.Add(placeNameTTL))
+	return name
+}
+
+// toViewModel turns a provider forecast into display values.
+func toViewModel(f *Forecast, u unitSet) WidgetViewModel {
+	vm := WidgetViewModel{
+		CurrentTemp:   f.Current.Temp,
+		TempUnit:      u.TempLabel,
+		FeelsLike:     f.Current.FeelsLike,
+		WindSpeed:     f.Current.WindSpeed,
+		WindUnit:      u.WindLabel,
+		Precipitation: f.Current.Precipitation,
+		PrecipUnit:    u.PrecipLabel,
+
+		Condition: f.Current.Condition.Text,
+		Icon:      f.Current.Condition.Icon,
+		Humidity:  f.Current.Humidity,
+		UVIndex:   f.Current.UVIndex,
+		Source:    f.Source,
+
+		NextHours: make([]HourForecast, 0, len(f.Hours)),
+		Days:      make([]DayForecast, 0, len(f.Days)),
+	}
+	if !f.Current.Time.IsZero() {
+		vm.UpdatedAt = f.Current.Time.Format("15:04")
 	}
 
-	// Build next-hours forecast (first few entries)
-	var next []HourForecast
-	max := len(api.Hourly.Time)
-	if max > 6 {
-		max = 6
+	// Day marks where a new (local) date starts, so long strips stay readable.
+	for i, h := range f.Hours {
+		var day string
+		if i > 0 && h.Time.YearDay() != f.Hours[i-1].Time.YearDay() {
+			day = h.Time.Format("Mon")
+		}
+		vm.NextHours = append(vm.NextHours, HourForecast{
+			Label:             h.Time.Format("15:04"),
+			Day:               day,
+			Temp:              h.Temp,
+			Time:              h.Time,
+			Condition:         h.Condition.Text,
+			Icon:              h.Condition.Icon,
+			PrecipProbability: h.PrecipProbability,
+			Precipitation:     h.Precipitation,
+		})
 	}
-	for i := 0; i < max; i++ {
-		t, err := time.Parse(time.RFC3339, api.Hourly.Time[i])
-		label := api.Hourly.Time[i]
-		if err == nil {
-			label = t.Format("15:04")
+	if len(f.Hours) > 0 {
+		vm.PrecipProbability = f.Hours[0].PrecipProbability
+	}
+
+	for i, d := range f.Days {
+		label := d.Date.Format("Mon")
+		if i == 0 {
+			label = "Today"
 		}
-		next = append(next, HourForecast{
-			Label: label,
-			Temp:  api.Hourly.Temperature2m[i],
-			Time:  t,
+		vm.Days = append(vm.Days, DayForecast{
+			Label:             label,
+			Date:              d.Date,
+			Condition:         d.Condition.Text,
+			Icon:              d.Condition.Icon,
+			High:              d.High,
+			Low:               d.Low,
+			PrecipSum:         d.PrecipSum,
+			PrecipProbability: d.PrecipProbability,
+			UVIndexMax:        d.UVIndexMax,
+			Sunrise:           clockLabel(d.Sunrise),
+			Sunset:            clockLabel(d.Sunset),
 		})
 	}
+	if len(vm.Days) > 0 {
+		vm.Sunrise, vm.Sunset = vm.Days[0].Sunrise, vm.Days[0].Sunset
+	}
+	return vm
+}
 
-	return WidgetViewModel{
-		LocationName: "New York, NY", // later: make dynamic
-		UpdatedAt:    updatedAt,
-		CurrentTemp:  api.Current.Temperature2m,
-		FeelsLike:    api.Current.ApparentTemperature,
-		WindSpeedKph: api.Current.WindSpeed10m, // already in km/h
-		NextHours:    next,
+// clockLabel is "15:04", or "" for an unknown time.
+func clockLabel(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
+	return t.Format("15:04")
 }
@@ -0,0 +1,122 @@
+package weather
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/patrickneise/dashboard/internal/auth"
+	"github.com/patrickneise/dashboard/internal/prefs"
+)
+
+const (
+	searchMinLen  = 2
+	searchMaxLen  = 100
+	searchResults = 8
+)
+
+var errNoPrefs = errors.New("weather: location picker needs a preferences service")
+
+// Mount adds the alerts banner (GET /alerts) and the location picker: a search box
+// (GET /location), results as you type (GET /location/search?q=), and saving or
+// resetting the user's location.
+func (w *Widget) Mount(r chi.Router) {
+	if w.alerts != nil {
+		r.Get("/alerts", w.alerts.ServeHTTP)
+	}
+	r.Get("/location", w.picker)
+	r.Get("/location/search", w.search)
+	r.Post("/location", w.setLocation)
+	r.Delete("/location", w.resetLocation)
+}
+
+func (w *Widget) picker(rw http.ResponseWriter, r *http.Request) {
+	w.render(rw, r, locationPicker(prefs.FromContext(r.Context()).HasLocation()))
+}
+
+func (w *Widget) search(rw http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if utf8.RuneCountInString(q) < searchMinLen || utf8.RuneCountInString(q) > searchMaxLen {
+		w.render(rw, r, locationResults(nil, ""))
+		return
+	}
+
+	places, err := w.client.SearchPlaces(r.Context(), q, w.lang, searchResults)
+	if err != nil {
+		w.log.WarnContext(r.Context(), "weather_location_search_failed", slog.Any("err", err))
+		w.render(rw, r, locationResults(nil, "Search is unavailable right now."))
+		return
+	}
+	if len(places) == 0 {
+		w.render(rw, r, locationResults(nil, "No places found."))
+		return
+	}
+	w.render(rw, r, locationResults(places, ""))
+}
+
+// setLocation saves a picked search result as the user's weather location and answers
+// with the widget for it.
+func (w *Widget) setLocation(rw http.ResponseWriter, r *http.Request) {
+	lat, errLat := strconv.ParseFloat(r.PostFormValue("lat"), 64)
+	lon, errLon := strconv.ParseFloat(r.PostFormValue("lon"), 64)
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 ||
+		utf8.RuneCountInString(name) > searchMaxLen {
+		http.Error(rw, "invalid location", http.StatusBadRequest)
+		return
+	}
+
+	w.savePrefs(rw, r, func(p *prefs.Preferences) {
+		p.Lat, p.Lon, p.LocationName = &lat, &lon, name
+	})
+}
+
+// resetLocation goes back to the configured default location.
+func (w *Widget) resetLocation(rw http.ResponseWriter, r *http.Request) {
+	w.savePrefs(rw, r, func(p *prefs.Preferences) {
+		p.Lat, p.Lon, p.LocationName = nil, nil, ""
+	})
+}
+
+// savePrefs applies change to the user's preferences, saves them, and re-renders the
+// widget with the new preferences in the request context (the variant changes, so
+// there is nothing to invalidate).
+func (w *Widget) savePrefs(rw http.ResponseWriter, r *http.Request, change func(p *prefs.Preferences)) {
+	if w.prefs == nil {
+		w.fail(rw, r, "weather_location_save_failed", errNoPrefs)
+		return
+	}
+	u, _ := auth.UserFromContext(r.Context())
+
+	p := prefs.FromContext(r.Context())
+	change(&p)
+	if err := w.prefs.Save(r.Context(), u.ID, p); err != nil {
+		w.fail(rw, r, "weather_location_save_failed", err)
+		return
+	}
+
+	w.ServeHTTP(rw, r.WithContext(prefs.WithPreferences(r.Context(), p)))
+}
+
+func (w *Widget) render(rw http.ResponseWriter, r *http.Request, c templ.Component) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	if err := c.Render(r.Context(), rw); err != nil {
+		w.log.ErrorContext(r.Context(), "weather_render_failed", slog.Any("err", err))
+	}
+}
+
+func (w *Widget) fail(rw http.ResponseWriter, r *http.Request, msg string, err error) {
+	w.log.ErrorContext(r.Context(), msg, slog.Any("err", err))
+	http.Error(rw, "something went wrong", http.StatusInternalServerError)
+}
+
+func coordValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
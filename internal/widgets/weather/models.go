@@ -4,18 +4,41 @@ type OpenMeteoResponse struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 
-	Timezone       string `json:"timezone"`
-	TimezoneAbbrev string `json:"timezone_abbreviation"`
+	Timezone         string `json:"timezone"`
+	TimezoneAbbrev   string `json:"timezone_abbreviation"`
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
 
 	Current struct {
 		Time                string  `json:"time"`
 		Temperature2m       float64 `json:"temperature_2m"`
 		ApparentTemperature float64 `json:"apparent_temperature"`
 		WindSpeed10m        float64 `json:"wind_speed_10m"`
+		Precipitation       float64 `json:"precipitation"`
+		RelativeHumidity2m  int     `json:"relative_humidity_2m"`
+		WeatherCode         int     `json:"weather_code"`
+		UVIndex             float64 `json:"uv_index"`
+		IsDay               int     `json:"is_day"` // 1 or 0
 	} `json:"current"`
 
 	Hourly struct {
-		Time          []string  `json:"time"`
-		Temperature2m []float64 `json:"temperature_2m"`
+		Time                     []string  `json:"time"`
+		Temperature2m            []float64 `json:"temperature_2m"`
+		WeatherCode              []int     `json:"weather_code"`
+		PrecipitationProbability []int     `json:"precipitation_probability"`
+		Precipitation            []float64 `json:"precipitation"`
+		IsDay                    []int     `json:"is_day"`
 	} `json:"hourly"`
+
+	// Daily times are dates ("2026-01-02"); sunrise/sunset are local times.
+	Daily struct {
+		Time                        []string  `json:"time"`
+		WeatherCode                 []int     `json:"weather_code"`
+		Temperature2mMax            []float64 `json:"temperature_2m_max"`
+		Temperature2mMin            []float64 `json:"temperature_2m_min"`
+		PrecipitationSum            []float64 `json:"precipitation_sum"`
+		PrecipitationProbabilityMax []int     `json:"precipitation_probability_max"`
+		UVIndexMax                  []float64 `json:"uv_index_max"`
+		Sunrise                     []string  `json:"sunrise"`
+		Sunset                      []string  `json:"sunset"`
+	} `json:"daily"`
 }
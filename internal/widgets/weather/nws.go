@@ -0,0 +1,286 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/cache"
+	"github.com/patrickneise/dashboard/internal/httpx"
+	"github.com/patrickneise/dashboard/internal/prefs"
+)
+
+const nwsAlertsURL = "https://api.weather.gov/alerts/active"
+
+// NWS is the US National Weather Service (api.weather.gov), as an alerts provider and
+// a forecast provider. Points outside the US and its territories have no alerts and
+// no forecast.
+type NWS struct {
+	http   *httpx.Client
+	points cache.Keyed[nwsPoint]
+}
+
+func NewNWS(h *httpx.Client) *NWS {
+	if h == nil {
+		h = httpx.New("dashboard/0.1")
+	}
+	return &NWS{http: h}
+}
+
+type nwsAlertsResponse struct {
+	Features []struct {
+		Properties struct {
+			Event    string    `json:"event"`
+			Headline string    `json:"headline"`
+			Severity string    `json:"severity"`
+			Expires  time.Time `json:"expires"`
+			Ends     time.Time `json:"ends"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+func (n *NWS) Alerts(ctx context.Context, lat, lon float64) ([]Alert, error) {
+	if !nwsCovers(lat, lon) {
+		return nil, nil
+	}
+
+	url := fmt.Sprintf("%s?point=%.4f,%.4f", nwsAlertsURL, lat, lon)
+
+	var data nwsAlertsResponse
+	if err := n.http.GetJSONAccept(ctx, url, "application/geo+json", &data); err != nil {
+		return nil, err
+	}
+
+	alerts := make([]Alert, 0, len(data.Features))
+	for _, f := range data.Features {
+		p := f.Properties
+		// "ends" is when the hazard is over; "expires" only when this message is
+		// superseded, so prefer the former when given.
+		expires := p.Expires
+		if !p.Ends.IsZero() {
+			expires = p.Ends
+		}
+		alerts = append(alerts, Alert{
+			Event:    p.Event,
+			Headline: p.Headline,
+			Severity: Severity(p.Severity),
+			Expires:  expires,
+		})
+	}
+	return alerts, nil
+}
+
+// nwsCovers roughly bounds the NWS forecast area: the contiguous US, Alaska, Hawaii,
+// Puerto Rico and the US Virgin Islands, and Guam and the Northern Marianas.
+func nwsCovers(lat, lon float64) bool {
+	in := func(minLat, maxLat, minLon, maxLon float64) bool {
+		return lat >= minLat && lat <= maxLat && lon >= minLon && lon <= maxLon
+	}
+	return in(24, 50, -125, -66) ||
+		in(51, 72, -180, -129) || in(51, 55, 172, 180) ||
+		in(18, 23, -161, -154) ||
+		in(17, 19, -68, -64) ||
+		in(13, 21, 144, 147)
+}
+
+const nwsPointsURL = "https://api.weather.gov/points"
+
+// nwsPointTTL bounds how long a point's gridpoint URLs are reused; NWS rarely moves
+// grids, but it does happen.
+const nwsPointTTL = 24 * time.Hour
+
+type nwsPoint struct {
+	Properties struct {
+		ForecastURL       string `json:"forecast"`
+		ForecastHourlyURL string `json:"forecastHourly"`
+		TimeZone          string `json:"timeZone"`
+	} `json:"properties"`
+}
+
+type nwsPeriod struct {
+	StartTime                  time.Time `json:"startTime"`
+	IsDaytime                  bool      `json:"isDaytime"`
+	Temperature                float64   `json:"temperature"`
+	WindSpeed                  string    `json:"windSpeed"` // "10 mph", "5 to 10 km/h"
+	ShortForecast              string    `json:"shortForecast"`
+	ProbabilityOfPrecipitation struct {
+		Value *float64 `json:"value"`
+	} `json:"probabilityOfPrecipitation"`
+	RelativeHumidity struct {
+		Value *float64 `json:"value"`
+	} `json:"relativeHumidity"`
+}
+
+type nwsForecast struct {
+	Properties struct {
+		Periods []nwsPeriod `json:"periods"`
+	} `json:"properties"`
+}
+
+var errNWSNotCovered = errors.New("nws: location outside the NWS forecast area")
+
+func (*NWS) Name() string { return "NWS" }
+
+// Forecast builds current conditions from the first hourly period (NWS has no
+// "current" endpoint short of picking an observation station) and days from the
+// day/night periods. NWS offers no feels-like, UV, sunrise/sunset or precipitation
+// amounts; those stay zero.
+func (n *NWS) Forecast(ctx context.Context, q Query) (*Forecast, error) {
+	if !nwsCovers(q.Lat, q.Lon) {
+		return nil, errNWSNotCovered
+	}
+
+	pt, err := n.point(ctx, q.Lat, q.Lon)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(pt.Properties.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	units := "us"
+	if q.Units == prefs.UnitsMetric {
+		units = "si"
+	}
+
+	var hourly, daily nwsForecast
+	if err := n.http.GetJSONAccept(ctx, pt.Properties.ForecastHourlyURL+"?units="+units, "application/geo+json", &hourly); err != nil {
+		return nil, err
+	}
+	if err := n.http.GetJSONAccept(ctx, pt.Properties.ForecastURL+"?units="+units, "application/geo+json", &daily); err != nil {
+		return nil, err
+	}
+
+	periods := hourly.Properties.Periods
+	if len(periods) == 0 {
+		return nil, errors.New("nws: empty hourly forecast")
+	}
+
+	f := &Forecast{Source: n.Name()}
+	for i, p := range periods {
+		if i == q.Hours {
+			break
+		}
+		f.Hours = append(f.Hours, Hour{
+			Time:              p.StartTime.In(loc),
+			Temp:              p.Temperature,
+			Condition:         nwsCondition(p.ShortForecast, p.IsDaytime),
+			PrecipProbability: percent(p.ProbabilityOfPrecipitation.Value),
+		})
+	}
+
+	now := periods[0]
+	f.Current = Current{
+		Time:      now.StartTime.In(loc),
+		Temp:      now.Temperature,
+		WindSpeed: nwsWindSpeed(now.WindSpeed),
+		Humidity:  percent(now.RelativeHumidity.Value),
+		Condition: nwsCondition(now.ShortForecast, now.IsDaytime),
+	}
+
+	f.Days = nwsDays(daily.Properties.Periods, loc, q.Days)
+	return f, nil
+}
+
+func (n *NWS) point(ctx context.Context, lat, lon float64) (nwsPoint, error) {
+	key := fmt.Sprintf("%.4f,%.4f", lat, lon)
+	now := time.Now()
+	if pt, _, state := n.points.Get(key, now); state == cache.Fresh {
+		return pt, nil
+	}
+
+	var pt nwsPoint
+	url := fmt.Sprintf("%s/%.4f,%.4f", nwsPointsURL, lat, lon)
+	if err := n.http.GetJSONAccept(ctx, url, "application/geo+json", &pt); err != nil {
+		return nwsPoint{}, err
+	}
+	if pt.Properties.ForecastURL == "" || pt.Properties.ForecastHourlyURL == "" {
+		return nwsPoint{}, errNWSNotCovered
+	}
+	n.points.Set(key, pt, now.Add(nwsPointTTL))
+	return pt, nil
+}
+
+// nwsDays folds day/night periods into days: the daytime period gives the high and
+// condition, the night the low. A day that starts at night ("Tonight") uses its low
+// for both.
+func nwsDays(periods []nwsPeriod, loc *time.Location, limit int) []Day {
+	var days []Day
+	for _, p := range periods {
+		start := p.StartTime.In(loc)
+		date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			if len(days) == limit {
+				break
+			}
+			days = append(days, Day{Date: date, High: p.Temperature, Low: p.Temperature})
+		}
+		d := &days[len(days)-1]
+
+		pop := percent(p.ProbabilityOfPrecipitation.Value)
+		d.PrecipProbability = max(d.PrecipProbability, pop)
+		if p.IsDaytime {
+			d.High = p.Temperature
+			d.Condition = nwsCondition(p.ShortForecast, true)
+		} else {
+			d.Low = p.Temperature
+			if d.Condition.Text == "" {
+				d.Condition = nwsCondition(p.ShortForecast, false)
+			}
+		}
+	}
+	return days
+}
+
+// nwsCondition keeps NWS's own wording and picks a matching icon.
+func nwsCondition(short string, isDay bool) condition {
+	s := strings.ToLower(short)
+	var icon string
+	switch {
+	case strings.Contains(s, "thunder"):
+		icon = "⛈️"
+	case strings.Contains(s, "snow"), strings.Contains(s, "flurr"), strings.Contains(s, "sleet"), strings.Contains(s, "ice"):
+		icon = "🌨️"
+	case strings.Contains(s, "rain"), strings.Contains(s, "shower"), strings.Contains(s, "drizzle"):
+		icon = "🌧️"
+	case strings.Contains(s, "fog"), strings.Contains(s, "haze"), strings.Contains(s, "smoke"):
+		icon = "🌫️"
+	case strings.Contains(s, "partly"):
+		icon = "⛅"
+	case strings.Contains(s, "cloudy"), strings.Contains(s, "overcast"):
+		icon = "☁️"
+	case strings.Contains(s, "mostly sunny"), strings.Contains(s, "mostly clear"):
+		icon = "🌤️"
+	case strings.Contains(s, "sunny"), strings.Contains(s, "clear"):
+		icon = "☀️"
+	default:
+		icon = "🌡️"
+	}
+	if !isDay && (icon == "☀️" || icon == "🌤️") {
+		icon = "🌙"
+	}
+	return condition{Text: short, Icon: icon}
+}
+
+// nwsWindSpeed takes the top of a range like "5 to 10 mph".
+func nwsWindSpeed(v string) float64 {
+	var top float64
+	for _, f := range strings.Fields(v) {
+		if n, err := strconv.ParseFloat(f, 64); err == nil {
+			top = n
+		}
+	}
+	return top
+}
+
+func percent(v *float64) int {
+	if v == nil {
+		return 0
+	}
+	return int(*v + 0.5)
+}
@@ -0,0 +1,104 @@
+package weather
+
+import (
+	"context"
+	"time"
+)
+
+// OpenMeteo is the Open-Meteo forecast provider (no API key, worldwide).
+type OpenMeteo struct {
+	Client *Client
+}
+
+func (OpenMeteo) Name() string { return "Open-Meteo" }
+
+func (o OpenMeteo) Forecast(ctx context.Context, q Query) (*Forecast, error) {
+	resp, err := o.Client.FetchForecast(ctx, q.Lat, q.Lon, q.Hours, q.Days, q.Units)
+	if err != nil {
+		return nil, err
+	}
+	return resp.forecast(q.Hours), nil
+}
+
+// Open-Meteo times are local to the location (timezone=auto) and carry no offset; the
+// zone comes from the response's timezone fields.
+const (
+	dateLayout      = "2006-01-02"
+	localTimeLayout = "2006-01-02T15:04"
+)
+
+func (api *OpenMeteoResponse) forecast(hours int) *Forecast {
+	loc := api.location()
+
+	f := &Forecast{
+		Source: OpenMeteo{}.Name(),
+		Current: Current{
+			Temp:          api.Current.Temperature2m,
+			FeelsLike:     &api.Current.ApparentTemperature,
+			WindSpeed:     api.Current.WindSpeed10m,
+			Precipitation: api.Current.Precipitation,
+			Humidity:      api.Current.RelativeHumidity2m,
+			UVIndex:       api.Current.UVIndex,
+			Condition:     conditionFor(api.Current.WeatherCode, api.Current.IsDay == 1),
+		},
+	}
+	f.Current.Time, _ = time.ParseInLocation(localTimeLayout, api.Current.Time, loc)
+
+	h := api.Hourly
+	for i := 0; i < min(len(h.Time), hours); i++ {
+		t, err := time.ParseInLocation(localTimeLayout, h.Time[i], loc)
+		if err != nil {
+			continue
+		}
+		f.Hours = append(f.Hours, Hour{
+			Time:              t,
+			Temp:              at(h.Temperature2m, i),
+			Condition:         conditionFor(at(h.WeatherCode, i), at(h.IsDay, i) == 1),
+			PrecipProbability: at(h.PrecipitationProbability, i),
+			Precipitation:     at(h.Precipitation, i),
+		})
+	}
+
+	d := api.Daily
+	for i, raw := range d.Time {
+		date, err := time.ParseInLocation(dateLayout, raw, loc)
+		if err != nil {
+			continue
+		}
+		sunrise, _ := time.ParseInLocation(localTimeLayout, at(d.Sunrise, i), loc)
+		sunset, _ := time.ParseInLocation(localTimeLayout, at(d.Sunset, i), loc)
+		f.Days = append(f.Days, Day{
+			Date:              date,
+			Condition:         conditionFor(at(d.WeatherCode, i), true),
+			High:              at(d.Temperature2mMax, i),
+			Low:               at(d.Temperature2mMin, i),
+			PrecipSum:         at(d.PrecipitationSum, i),
+			PrecipProbability: at(d.PrecipitationProbabilityMax, i),
+			UVIndexMax:        at(d.UVIndexMax, i),
+			Sunrise:           sunrise,
+			Sunset:            sunset,
+		})
+	}
+	return f
+}
+
+// location returns the forecast's time zone: the named IANA zone when it is known here
+// (correct across DST changes within the horizon), else the fixed offset.
+func (api *OpenMeteoResponse) location() *time.Location {
+	if api.Timezone != "" {
+		if loc, err := time.LoadLocation(api.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.FixedZone(api.TimezoneAbbrev, api.UTCOffsetSeconds)
+}
+
+// at returns xs[i], or the zero value when the series is shorter (a variable the
+// provider didn't return for this location).
+func at[T any](xs []T, i int) T {
+	if i < 0 || i >= len(xs) {
+		var zero T
+		return zero
+	}
+	return xs[i]
+}
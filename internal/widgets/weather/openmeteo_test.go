@@ -0,0 +1,206 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/httpx"
+	"github.com/patrickneise/dashboard/internal/prefs"
+)
+
+// fixtureTransport answers every request with a recorded response from testdata and
+// remembers the last request.
+type fixtureTransport struct {
+	t    *testing.T
+	file string
+	req  *http.Request
+}
+
+func (f *fixtureTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.req = r
+	body, err := os.Open(filepath.Join("testdata", f.file))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       body,
+		Request:    r,
+	}, nil
+}
+
+// forecastFromFixture runs the Open-Meteo provider against a recorded response.
+func forecastFromFixture(t *testing.T, file string, q Query) (*Forecast, *http.Request) {
+	t.Helper()
+	rt := &fixtureTransport{t: t, file: file}
+	h := httpx.New("test")
+	h.HTTP = &http.Client{Transport: rt}
+	h.Retries = 0
+
+	f, err := OpenMeteo{Client: NewClient(h)}.Forecast(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, rt.req
+}
+
+// compareViewModels checks got against want, comparing times with Equal (zones are
+// loaded separately, so pointers differ).
+func compareViewModels(t *testing.T, got, want WidgetViewModel) {
+	t.Helper()
+	if len(got.NextHours) != len(want.NextHours) || len(got.Days) != len(want.Days) {
+		t.Fatalf("got %d hours and %d days, want %d and %d",
+			len(got.NextHours), len(got.Days), len(want.NextHours), len(want.Days))
+	}
+	for i := range got.NextHours {
+		if !got.NextHours[i].Time.Equal(want.NextHours[i].Time) {
+			t.Errorf("hour %d time = %v, want %v", i, got.NextHours[i].Time, want.NextHours[i].Time)
+		}
+		got.NextHours[i].Time, want.NextHours[i].Time = time.Time{}, time.Time{}
+	}
+	for i := range got.Days {
+		if !got.Days[i].Date.Equal(want.Days[i].Date) {
+			t.Errorf("day %d date = %v, want %v", i, got.Days[i].Date, want.Days[i].Date)
+		}
+		got.Days[i].Date, want.Days[i].Date = time.Time{}, time.Time{}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("view model mismatch\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestOpenMeteoFixture(t *testing.T) {
+	f, req := forecastFromFixture(t, "openmeteo_forecast.json",
+		Query{Lat: 40.7103, Lon: -73.9931, Hours: 6, Days: 3, Units: prefs.UnitsImperial})
+
+	q := req.URL.Query()
+	for param, want := range map[string]string{
+		"forecast_hours":     "6",
+		"forecast_days":      "3",
+		"temperature_unit":   "fahrenheit",
+		"wind_speed_unit":    "mph",
+		"precipitation_unit": "inch",
+		"timezone":           "auto",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("query %s = %q, want %q", param, got, want)
+		}
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	local := func(day, hour int) time.Time { return time.Date(2026, time.January, day, hour, 0, 0, 0, ny) }
+
+	got := toViewModel(f, imperialUnits)
+	want := WidgetViewModel{
+		UpdatedAt:     "22:00",
+		CurrentTemp:   28.4,
+		TempUnit:      "°F",
+		FeelsLike:     ptr(19.9),
+		WindSpeed:     9.6,
+		WindUnit:      "mph",
+		Precipitation: 0,
+		PrecipUnit:    "in",
+
+		// Clear at night gets the moon.
+		Condition:         "Clear",
+		Icon:              "🌙",
+		Humidity:          61,
+		UVIndex:           0,
+		PrecipProbability: 0,
+		Sunrise:           "07:18",
+		Sunset:            "16:51",
+		Source:            "Open-Meteo",
+
+		NextHours: []HourForecast{
+			{Label: "22:00", Temp: 28.4, Time: local(14, 22), Condition: "Clear", Icon: "🌙"},
+			{Label: "23:00", Temp: 27.9, Time: local(14, 23), Condition: "Mostly clear", Icon: "🌙", PrecipProbability: 3},
+			{Label: "00:00", Day: "Thu", Temp: 27.1, Time: local(15, 0), Condition: "Overcast", Icon: "☁️", PrecipProbability: 15},
+			{Label: "01:00", Temp: 26.6, Time: local(15, 1), Condition: "Light snow", Icon: "🌨️", PrecipProbability: 45, Precipitation: 0.02},
+			{Label: "02:00", Temp: 26.8, Time: local(15, 2), Condition: "Snow", Icon: "🌨️", PrecipProbability: 70, Precipitation: 0.05},
+			{Label: "03:00", Temp: 27.3, Time: local(15, 3), Condition: "Snow", Icon: "🌨️", PrecipProbability: 80, Precipitation: 0.06},
+		},
+		Days: []DayForecast{
+			{Label: "Today", Date: local(14, 0), Condition: "Overcast", Icon: "☁️", High: 36.1, Low: 25.3,
+				PrecipProbability: 10, UVIndexMax: 2.15, Sunrise: "07:18", Sunset: "16:51"},
+			{Label: "Thu", Date: local(15, 0), Condition: "Snow", Icon: "🌨️", High: 31.5, Low: 24.8,
+				PrecipSum: 0.31, PrecipProbability: 85, UVIndexMax: 1.05, Sunrise: "07:18", Sunset: "16:52"},
+			{Label: "Fri", Date: local(16, 0), Condition: "Light rain", Icon: "🌦️", High: 41.0, Low: 30.2,
+				PrecipSum: 0.12, PrecipProbability: 40, UVIndexMax: 1.80, Sunrise: "07:17", Sunset: "16:53"},
+		},
+	}
+	compareViewModels(t, got, want)
+}
+
+// The partial fixture lacks the UV index, precipitation probability and sunrise/sunset
+// series, has a short weather code series, and names a zone unknown here.
+func TestOpenMeteoFixtureMissingSeries(t *testing.T) {
+	f, _ := forecastFromFixture(t, "openmeteo_partial.json",
+		Query{Lat: 51.5, Lon: -0.12, Hours: 3, Days: 2, Units: prefs.UnitsMetric})
+
+	// Unknown zone: falls back to the fixed offset.
+	zone := time.FixedZone("GMT+1", 3600)
+	if name, offset := f.Current.Time.Zone(); name != "GMT+1" || offset != 3600 {
+		t.Errorf("current time zone = %s %d, want GMT+1 3600", name, offset)
+	}
+	local := func(day, hour int) time.Time { return time.Date(2026, time.June, day, hour, 0, 0, 0, zone) }
+
+	got := toViewModel(f, metricUnits)
+	want := WidgetViewModel{
+		UpdatedAt:     "13:00",
+		CurrentTemp:   19.2,
+		TempUnit:      "°C",
+		FeelsLike:     ptr(18.5),
+		WindSpeed:     14.8,
+		WindUnit:      "km/h",
+		Precipitation: 0.4,
+		PrecipUnit:    "mm",
+
+		Condition: "Light rain",
+		Icon:      "🌦️",
+		Humidity:  72,
+		Source:    "Open-Meteo",
+
+		NextHours: []HourForecast{
+			{Label: "13:00", Temp: 19.2, Time: local(2, 13), Condition: "Light rain", Icon: "🌦️", Precipitation: 0.4},
+			{Label: "14:00", Temp: 19.8, Time: local(2, 14), Condition: "Partly cloudy", Icon: "⛅"},
+			// No weather code for the last hour: code 0.
+			{Label: "15:00", Temp: 20.1, Time: local(2, 15), Condition: "Clear", Icon: "☀️"},
+		},
+		Days: []DayForecast{
+			{Label: "Today", Date: local(2, 0), Condition: "Light rain", Icon: "🌦️", High: 21.4, Low: 12.0, PrecipSum: 2.6},
+			{Label: "Wed", Date: local(3, 0), Condition: "Fog", Icon: "🌫️", High: 18.9, Low: 11.3},
+		},
+	}
+	compareViewModels(t, got, want)
+}
+
+func TestConditionFor(t *testing.T) {
+	tests := []struct {
+		code  int
+		isDay bool
+		want  condition
+	}{
+		{0, true, condition{"Clear", "☀️"}},
+		{0, false, condition{"Clear", "🌙"}},
+		{1, false, condition{"Mostly clear", "🌙"}},
+		{2, false, condition{"Partly cloudy", "⛅"}},
+		{95, true, condition{"Thunderstorm", "⛈️"}},
+		{42, true, condition{"Unknown", "🌡️"}},
+	}
+	for _, tt := range tests {
+		if got := conditionFor(tt.code, tt.isDay); got != tt.want {
+			t.Errorf("conditionFor(%d, %v) = %+v, want %+v", tt.code, tt.isDay, got, tt.want)
+		}
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
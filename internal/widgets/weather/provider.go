@@ -0,0 +1,97 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/prefs"
+)
+
+// Provider is a forecast source. Implementations return every value in q.Units and
+// times in the location's own zone.
+type Provider interface {
+	Name() string // shown as the data source, e.g. "Open-Meteo"
+	Forecast(ctx context.Context, q Query) (*Forecast, error)
+}
+
+type Query struct {
+	Lat, Lon float64
+	Hours    int
+	Days     int
+	Units    prefs.Units
+}
+
+// Forecast is the provider-neutral forecast. Fields a provider doesn't offer stay zero
+// or nil (e.g. NWS has no UV index, sunrise or feels-like temperature).
+type Forecast struct {
+	Source  string
+	Current Current
+	Hours   []Hour
+	Days    []Day
+}
+
+type Current struct {
+	Time          time.Time
+	Temp          float64
+	FeelsLike     *float64 // nil when the provider doesn't report it (NWS)
+	WindSpeed     float64
+	Precipitation float64
+	Humidity      int // percent
+	UVIndex       float64
+	Condition     condition
+}
+
+type Hour struct {
+	Time              time.Time
+	Temp              float64
+	Condition         condition
+	PrecipProbability int // percent
+	Precipitation     float64
+}
+
+type Day struct {
+	Date              time.Time // local midnight
+	Condition         condition
+	High, Low         float64
+	PrecipSum         float64
+	PrecipProbability int // daily maximum, percent
+	UVIndexMax        float64
+	Sunrise, Sunset   time.Time
+}
+
+// Failover tries providers in order and returns the first forecast. Only when all of
+// them fail does the error reach widgetkit, which then falls back to stale data.
+type Failover struct {
+	Providers []Provider
+	Log       *slog.Logger
+}
+
+func (f Failover) Name() string {
+	if len(f.Providers) == 0 {
+		return ""
+	}
+	return f.Providers[0].Name()
+}
+
+func (f Failover) Forecast(ctx context.Context, q Query) (*Forecast, error) {
+	var errs []error
+	for i, p := range f.Providers {
+		fc, err := p.Forecast(ctx, q)
+		if err == nil {
+			return fc, nil
+		}
+		errs = append(errs, err)
+		if f.Log != nil && i < len(f.Providers)-1 {
+			f.Log.Warn("weather_provider_failed_trying_next",
+				slog.String("provider", p.Name()),
+				slog.String("next", f.Providers[i+1].Name()),
+				slog.Any("err", err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("weather: no providers configured")
+	}
+	return nil, errors.Join(errs...)
+}
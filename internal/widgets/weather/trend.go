@@ -0,0 +1,31 @@
+package weather
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+// trendWindow is how far back the temperature sparkline reaches.
+const trendWindow = 24 * time.Hour
+
+// withTrend fills Trend24h with the temperatures recorded over trendWindow, ending with
+// v's own reading.
+func withTrend(v WidgetViewModel, past []widgetkit.Point[WidgetViewModel]) WidgetViewModel {
+	if len(past) == 0 {
+		return v
+	}
+	temps := make([]float64, 0, len(past)+1)
+	for _, p := range past {
+		temps = append(temps, p.Value.CurrentTemp)
+	}
+	v.Trend24h = append(temps, v.CurrentTemp)
+	return v
+}
+
+// trendLabel summarizes the trend's range, e.g. "41.2–55.0°F".
+func trendLabel(temps []float64, unit string) string {
+	return fmt.Sprintf("%.1f–%.1f%s", slices.Min(temps), slices.Max(temps), unit)
+}
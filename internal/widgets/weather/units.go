@@ -0,0 +1,43 @@
+package weather
+
+import (
+	"fmt"
+
+	"github.com/patrickneise/dashboard/internal/prefs"
+)
+
+// unitSet is a measurement system as Open-Meteo query values plus display labels.
+type unitSet struct {
+	// Open-Meteo temperature_unit, wind_speed_unit and precipitation_unit
+	temperature, windSpeed, precipitation string
+
+	// Labels shown next to values
+	TempLabel, WindLabel, PrecipLabel string
+}
+
+var (
+	imperialUnits = unitSet{
+		temperature: "fahrenheit", windSpeed: "mph", precipitation: "inch",
+		TempLabel: "°F", WindLabel: "mph", PrecipLabel: "in",
+	}
+	metricUnits = unitSet{
+		temperature: "celsius", windSpeed: "kmh", precipitation: "mm",
+		TempLabel: "°C", WindLabel: "km/h", PrecipLabel: "mm",
+	}
+)
+
+// unitsFor maps a preference to its unit set; anything but metric is imperial.
+func unitsFor(u prefs.Units) unitSet {
+	if u == prefs.UnitsMetric {
+		return metricUnits
+	}
+	return imperialUnits
+}
+
+// precipLabel formats an amount with the precision its unit needs ("0.04 in", "1.2 mm").
+func precipLabel(v float64, unit string) string {
+	if unit == imperialUnits.PrecipLabel {
+		return fmt.Sprintf("%.2f %s", v, unit)
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
@@ -1,21 +1,85 @@
 package weather
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
+// WidgetViewModel carries every value in one unit system, labeled by the *Unit fields.
 type WidgetViewModel struct {
-	LocationName string
-	UpdatedAt    string
-	CurrentTemp  float64
-	FeelsLike    float64
-	WindSpeedKph float64
-	NextHours    []HourForecast
-
-	IsStale bool
-	StaleBy string
+	LocationName  string         `json:"location_name"`
+	UpdatedAt     string         `json:"updated_at"`
+	CurrentTemp   float64        `json:"current_temp"`
+	TempUnit      string         `json:"temp_unit"` // "°F" or "°C"
+	FeelsLike     *float64       `json:"feels_like,omitempty"`
+	WindSpeed     float64        `json:"wind_speed"`
+	WindUnit      string         `json:"wind_unit"` // "mph" or "km/h"
+	Precipitation float64        `json:"precipitation"`
+	PrecipUnit    string         `json:"precip_unit"` // "in" or "mm"
+	NextHours     []HourForecast `json:"next_hours"`
+
+	Condition         string  `json:"condition"` // e.g. "Light rain"
+	Icon              string  `json:"icon"`
+	Humidity          int     `json:"humidity"`           // percent
+	UVIndex           float64 `json:"uv_index"`           // current
+	PrecipProbability int     `json:"precip_probability"` // percent, this hour
+	Sunrise           string  `json:"sunrise,omitempty"`  // today, e.g. "07:12"
+	Sunset            string  `json:"sunset,omitempty"`
+
+	Days []DayForecast `json:"days"`
+
+	// Source is the provider that answered (after failover), e.g. "Open-Meteo".
+	Source string `json:"source"`
+
+	// Trend24h is the current temperature as recorded over the past day, oldest first.
+	Trend24h []float64 `json:"trend_24h,omitempty"`
+
+	IsStale bool   `json:"is_stale"`
+	StaleBy string `json:"stale_by,omitempty"`
 }
 
 type HourForecast struct {
-	Label string // e.g. "14:00"
-	Temp  float64
-	Time  time.Time
+	Label string    `json:"label"`         // local time, e.g. "14:00"
+	Day   string    `json:"day,omitempty"` // weekday on the first hour of a new day
+	Temp  float64   `json:"temp"`
+	Time  time.Time `json:"time"`
+
+	Condition         string  `json:"condition"`
+	Icon              string  `json:"icon"`
+	PrecipProbability int     `json:"precip_probability"`
+	Precipitation     float64 `json:"precipitation"`
+}
+
+type DayForecast struct {
+	Label string    `json:"label"` // "Today", then weekday names
+	Date  time.Time `json:"date"`
+
+	Condition         string  `json:"condition"`
+	Icon              string  `json:"icon"`
+	High              float64 `json:"high"`
+	Low               float64 `json:"low"`
+	PrecipSum         float64 `json:"precip_sum"`
+	PrecipProbability int     `json:"precip_probability"` // daily maximum, percent
+	UVIndexMax        float64 `json:"uv_index_max"`
+	Sunrise           string  `json:"sunrise,omitempty"`
+	Sunset            string  `json:"sunset,omitempty"`
+}
+
+// chartMinHours is the horizon above which the hourly strip also gets a temperature
+// chart, since most of the strip is then scrolled out of view.
+const chartMinHours = 12
+
+func hourlyTemps(hours []HourForecast) []float64 {
+	temps := make([]float64, len(hours))
+	for i, h := range hours {
+		temps[i] = h.Temp
+	}
+	return temps
+}
+
+func hoursHeading(n int) string {
+	if n > chartMinHours {
+		return fmt.Sprintf("Next %d hours", n)
+	}
+	return "Next hours"
 }
@@ -0,0 +1,51 @@
+package weather
+
+// condition is the display form of a WMO weather interpretation code.
+type condition struct {
+	Text string
+	Icon string // emoji, so the widget needs no image assets
+}
+
+// wmoConditions covers the codes Open-Meteo returns (WMO 4677, simplified).
+var wmoConditions = map[int]condition{
+	0:  {"Clear", "☀️"},
+	1:  {"Mostly clear", "🌤️"},
+	2:  {"Partly cloudy", "⛅"},
+	3:  {"Overcast", "☁️"},
+	45: {"Fog", "🌫️"},
+	48: {"Freezing fog", "🌫️"},
+	51: {"Light drizzle", "🌦️"},
+	53: {"Drizzle", "🌦️"},
+	55: {"Heavy drizzle", "🌧️"},
+	56: {"Freezing drizzle", "🌧️"},
+	57: {"Heavy freezing drizzle", "🌧️"},
+	61: {"Light rain", "🌦️"},
+	63: {"Rain", "🌧️"},
+	65: {"Heavy rain", "🌧️"},
+	66: {"Freezing rain", "🌧️"},
+	67: {"Heavy freezing rain", "🌧️"},
+	71: {"Light snow", "🌨️"},
+	73: {"Snow", "🌨️"},
+	75: {"Heavy snow", "❄️"},
+	77: {"Snow grains", "🌨️"},
+	80: {"Light showers", "🌦️"},
+	81: {"Showers", "🌧️"},
+	82: {"Violent showers", "⛈️"},
+	85: {"Snow showers", "🌨️"},
+	86: {"Heavy snow showers", "❄️"},
+	95: {"Thunderstorm", "⛈️"},
+	96: {"Thunderstorm with hail", "⛈️"},
+	99: {"Thunderstorm with heavy hail", "⛈️"},
+}
+
+// conditionFor maps a WMO code to text and icon; clear skies get a moon at night.
+func conditionFor(code int, isDay bool) condition {
+	c, ok := wmoConditions[code]
+	if !ok {
+		return condition{Text: "Unknown", Icon: "🌡️"}
+	}
+	if !isDay && code <= 1 {
+		c.Icon = "🌙"
+	}
+	return c
+}
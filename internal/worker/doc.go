@@ -0,0 +1,4 @@
+// Package worker refreshes widget snapshots on a schedule, independently of the web
+// process. Each widget whose handler implements widgetkit.Syncer runs on its own
+// interval; failures are retried sooner than the regular schedule.
+package worker
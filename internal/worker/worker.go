@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/patrickneise/dashboard/internal/widgetkit"
+)
+
+// job is one widget the worker keeps in sync.
+type job struct {
+	key    string
+	syncer widgetkit.Syncer
+}
+
+type Worker struct {
+	jobs []job
+	log  *slog.Logger
+}
+
+// New collects the syncable widgets from reg. Widgets without snapshot support (or
+// with nothing to sync, like per-user data) are skipped with a log line.
+func New(reg *widgetkit.Registry, log *slog.Logger) *Worker {
+	w := &Worker{log: log}
+	for _, s := range reg.List() {
+		sy, ok := s.Handler.(widgetkit.Syncer)
+		if !ok || sy.SyncInterval() <= 0 {
+			log.Info("worker_widget_skipped", slog.String("widget", s.Key))
+			continue
+		}
+		w.jobs = append(w.jobs, job{key: s.Key, syncer: sy})
+	}
+	return w
+}
+
+// RunOnce syncs every widget concurrently and returns the joined errors.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, j := range w.jobs {
+		wg.Go(func() {
+			if err := w.sync(ctx, j); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", j.key, err))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Run syncs every widget immediately and then on its own interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) error {
+	if len(w.jobs) == 0 {
+		return errors.New("worker: no syncable widgets registered")
+	}
+
+	var wg sync.WaitGroup
+	for _, j := range w.jobs {
+		wg.Go(func() { w.loop(ctx, j) })
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *Worker) loop(ctx context.Context, j job) {
+	interval := j.syncer.SyncInterval()
+	retry := max(interval/4, 5*time.Second)
+
+	for {
+		next := interval
+		if err := w.sync(ctx, j); err != nil {
+			next = retry
+		}
+
+		t := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (w *Worker) sync(ctx context.Context, j job) error {
+	start := time.Now()
+	err := j.syncer.Sync(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.log.Error("widget_sync_failed",
+				slog.String("widget", j.key),
+				slog.Duration("duration", time.Since(start)),
+				slog.Any("err", err))
+		}
+		return err
+	}
+
+	w.log.Info("widget_synced",
+		slog.String("widget", j.key),
+		slog.Duration("duration", time.Since(start)))
+	return nil
+}
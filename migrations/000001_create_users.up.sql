@@ -0,0 +1,7 @@
+CREATE TABLE users (
+    id            INTEGER   PRIMARY KEY AUTOINCREMENT,
+    username      TEXT      NOT NULL UNIQUE COLLATE NOCASE,
+    password_hash TEXT      NOT NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP
+);
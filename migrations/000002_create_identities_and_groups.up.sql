@@ -0,0 +1,13 @@
+CREATE TABLE user_identities (
+    issuer     TEXT      NOT NULL,
+    subject    TEXT      NOT NULL,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (issuer, subject)
+);
+
+CREATE TABLE user_groups (
+    user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    group_name TEXT    NOT NULL,
+    PRIMARY KEY (user_id, group_name)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE user_preferences (
+    user_id        INTEGER   PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    lat            REAL,
+    lon            REAL,
+    location_name  TEXT,
+    units          TEXT,
+    hn_count       INTEGER,
+    hidden_widgets TEXT      NOT NULL DEFAULT '',
+    updated_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
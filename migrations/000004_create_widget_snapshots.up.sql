@@ -0,0 +1,8 @@
+CREATE TABLE widget_snapshots (
+    widget     TEXT      NOT NULL,
+    variant    TEXT      NOT NULL DEFAULT '',
+    data       BLOB      NOT NULL,
+    fetched_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (widget, variant)
+);
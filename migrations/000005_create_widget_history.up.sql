@@ -0,0 +1,9 @@
+CREATE TABLE widget_history (
+    id         INTEGER   PRIMARY KEY,
+    widget     TEXT      NOT NULL,
+    variant    TEXT      NOT NULL DEFAULT '',
+    data       BLOB      NOT NULL,
+    fetched_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX widget_history_lookup ON widget_history (widget, variant, fetched_at);
@@ -0,0 +1,13 @@
+CREATE TABLE bookmarks (
+    id         INTEGER   PRIMARY KEY,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    group_name TEXT      NOT NULL DEFAULT '',
+    name       TEXT      NOT NULL,
+    url        TEXT      NOT NULL,
+    icon       TEXT      NOT NULL DEFAULT '',
+    tags       TEXT      NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX bookmarks_user ON bookmarks (user_id, group_name);
@@ -0,0 +1,17 @@
+CREATE TABLE todos (
+    id         INTEGER   PRIMARY KEY,
+    text       TEXT      NOT NULL,
+    done       BOOLEAN   NOT NULL DEFAULT false,
+    position   INTEGER   NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    done_at    TIMESTAMP
+);
+
+CREATE INDEX todos_position ON todos (position);
+
+CREATE TABLE notes (
+    id         INTEGER   PRIMARY KEY,
+    body       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations so the binary can apply them
+// without the migrate CLI or a checkout of the repository.
+package migrations
+
+import "embed"
+
+// FS holds the numbered up/down migration files (golang-migrate naming).
+//
+//go:embed *.sql
+var FS embed.FS
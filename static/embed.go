@@ -0,0 +1,11 @@
+// Package static embeds the served assets (vendor JS and the Tailwind output) so the
+// binary doesn't depend on its working directory.
+package static
+
+import "embed"
+
+// FS holds js/ and css/. css/output.css is produced by `make css` before `go build`; the
+// .gitkeep keeps the directory embeddable in a fresh checkout.
+//
+//go:embed all:js all:css
+var FS embed.FS